@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/pavbis/repositories-api/application/storage"
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+type apiKeyAuthenticator struct {
+	store storage.UsesAPIKeys
+}
+
+// NewAPIKeyAuthenticator creates authenticator which accepts the api keys
+// provided via X-API-Key header or as bearer token
+func NewAPIKeyAuthenticator(store storage.UsesAPIKeys) Authenticator {
+	return &apiKeyAuthenticator{store: store}
+}
+
+// Authenticate looks the api key up by the hash of the provided secret
+func (a *apiKeyAuthenticator) Authenticate(r *http.Request) (*types.Principal, error) {
+	secret := r.Header.Get("X-API-Key")
+
+	if secret == "" {
+		secret = bearerToken(r)
+	}
+
+	if !strings.HasPrefix(secret, types.APIKeySecretPrefix) {
+		return nil, ErrInvalidCredentials
+	}
+
+	key, err := a.store.UseAPIKey(types.HashAPIKeySecret(secret))
+
+	if err != nil {
+		if errors.Is(err, storage.ErrAPIKeyNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	return &types.Principal{Name: "api-key:" + key.Name, Roles: key.Scopes}, nil
+}
+
+// bearerToken returns the token of the bearer authorization header
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
+
+type chainAuthenticator struct {
+	authenticators []Authenticator
+}
+
+// NewChainAuthenticator creates authenticator which accepts the request if any of the provided authenticators does
+func NewChainAuthenticator(authenticators ...Authenticator) Authenticator {
+	return &chainAuthenticator{authenticators: authenticators}
+}
+
+// Authenticate asks the authenticators in order and returns the first principal,
+// the failure of an authenticator is returned unless another one accepts the credentials
+func (a *chainAuthenticator) Authenticate(r *http.Request) (*types.Principal, error) {
+	var failure error
+
+	for _, authenticator := range a.authenticators {
+		principal, err := authenticator.Authenticate(r)
+
+		if err == nil {
+			return principal, nil
+		}
+
+		if failure == nil && !errors.Is(err, ErrInvalidCredentials) {
+			failure = err
+		}
+	}
+
+	if failure != nil {
+		return nil, failure
+	}
+
+	return nil, ErrInvalidCredentials
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pavbis/repositories-api/application/storage"
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+const testAPIKeySecret = "rk_test-secret"
+
+// FakeAPIKeyStore knows exactly one active api key
+type FakeAPIKeyStore struct{}
+
+func (s *FakeAPIKeyStore) UseAPIKey(secretHash string) (*types.APIKey, error) {
+	if secretHash != types.HashAPIKeySecret(testAPIKeySecret) {
+		return nil, storage.ErrAPIKeyNotFound
+	}
+
+	return &types.APIKey{Name: "ci", Scopes: []types.Role{types.ImporterRole}}, nil
+}
+
+// FailingAPIKeyStore can not look api keys up
+type FailingAPIKeyStore struct{}
+
+func (s *FailingAPIKeyStore) UseAPIKey(string) (*types.APIKey, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	tests := []struct {
+		name          string
+		header        string
+		value         string
+		expectedError bool
+	}{
+		{
+			name:          "Test with X-API-Key header",
+			header:        "X-API-Key",
+			value:         testAPIKeySecret,
+			expectedError: false,
+		},
+		{
+			name:          "Test with bearer token",
+			header:        "Authorization",
+			value:         "Bearer " + testAPIKeySecret,
+			expectedError: false,
+		},
+		{
+			name:          "Test with unknown key",
+			header:        "X-API-Key",
+			value:         "rk_unknown",
+			expectedError: true,
+		},
+		{
+			name:          "Test with other bearer token",
+			header:        "Authorization",
+			value:         "Bearer eyJhbGciOiJSUzI1NiJ9",
+			expectedError: true,
+		},
+		{
+			name:          "Test without header",
+			expectedError: true,
+		},
+	}
+
+	authenticator := NewAPIKeyAuthenticator(&FakeAPIKeyStore{})
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, dummyURL, nil)
+
+		if test.header != "" {
+			req.Header.Set(test.header, test.value)
+		}
+
+		principal, err := authenticator.Authenticate(req)
+
+		if (err != nil) != test.expectedError {
+			t.Errorf("for api key test '%s', got error %v but expected error %t", test.name, err, test.expectedError)
+		}
+
+		if err == nil && (principal.Name != "api-key:ci" || !principal.HasRole(types.ImporterRole)) {
+			t.Errorf("for api key test '%s', got unexpected principal %v", test.name, principal)
+		}
+	}
+}
+
+func TestChainAuthenticator(t *testing.T) {
+	authenticator := NewChainAuthenticator(testAuthenticator(), NewAPIKeyAuthenticator(&FakeAPIKeyStore{}))
+
+	req := httptest.NewRequest(http.MethodGet, dummyURL, nil)
+	req.Header.Set("X-API-Key", testAPIKeySecret)
+
+	if principal, err := authenticator.Authenticate(req); err != nil || principal.Name != "api-key:ci" {
+		t.Errorf("Expected api key principal. Got %v, %v", principal, err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, dummyURL, nil)
+	req.SetBasicAuth(testUser, testPass)
+
+	if principal, err := authenticator.Authenticate(req); err != nil || principal.Name != testUser {
+		t.Errorf("Expected basic auth principal. Got %v, %v", principal, err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, dummyURL, nil)
+
+	if _, err := authenticator.Authenticate(req); err == nil {
+		t.Error("Expected request without credentials to be rejected")
+	}
+}
+
+func TestChainAuthenticatorReportsFailures(t *testing.T) {
+	authenticator := NewChainAuthenticator(testAuthenticator(), NewAPIKeyAuthenticator(&FailingAPIKeyStore{}))
+
+	req := httptest.NewRequest(http.MethodGet, dummyURL, nil)
+	req.Header.Set("X-API-Key", testAPIKeySecret)
+
+	if _, err := authenticator.Authenticate(req); err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected the store failure. Got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, dummyURL, nil)
+	req.SetBasicAuth(testUser, testPass)
+
+	if principal, err := authenticator.Authenticate(req); err != nil || principal.Name != testUser {
+		t.Errorf("Expected basic auth principal. Got %v, %v", principal, err)
+	}
+
+	rr := httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, dummyURL, nil)
+	req.Header.Set("X-API-Key", testAPIKeySecret)
+	AuthenticationMiddleware(authenticator, func(http.ResponseWriter, *http.Request) {})(rr, req)
+
+	if rr.Code != http.StatusInternalServerError || rr.Header().Get("WWW-Authenticate") != "" {
+		t.Errorf("Expected internal server error without challenge. Got %d", rr.Code)
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pavbis/repositories-api/api/input"
+	"github.com/pavbis/repositories-api/application/storage"
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+// CreateAPIKeyRequestHandler handles incoming request and executes storage's create api key operation,
+// the secret is part of this response only
+func CreateAPIKeyRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	audit := newAuditTrail(db, r, types.APIKeyCreateAction)
+	defer audit.record()
+
+	apiKeyRequest, err := input.NewCreateAPIKeyRequest(r)
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	audit.addTargets(apiKeyRequest.Name)
+
+	if err = newValidator().Struct(apiKeyRequest); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	secret, err := types.GenerateAPIKeySecret()
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	scopes := make([]types.Role, 0, len(apiKeyRequest.Scopes))
+
+	for _, scope := range apiKeyRequest.Scopes {
+		scopes = append(scopes, types.Role(scope))
+	}
+
+	apiKeyStore := storage.NewPostgresAPIKeyStore(db)
+	result, err := apiKeyStore.CreateAPIKey(&types.APIKey{
+		Name:      apiKeyRequest.Name,
+		Prefix:    types.APIKeySecretPrefixOf(secret),
+		Scopes:    scopes,
+		ExpiresAt: apiKeyRequest.ExpiresAt,
+	}, types.HashAPIKeySecret(secret))
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	audit.succeeded(result.UUID.String())
+	respondWithJSON(w, http.StatusCreated, types.CreatedAPIKey{APIKey: *result, Key: secret})
+}
+
+// ListAPIKeysRequestHandler executes storage's read api keys operation
+func ListAPIKeysRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	apiKeyStore := storage.NewPostgresAPIKeyStore(db)
+	result, err := apiKeyStore.ReadAPIKeys()
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// RevokeAPIKeyRequestHandler handles incoming request and executes storage's revoke api key operation
+func RevokeAPIKeyRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	audit := newAuditTrail(db, r, types.APIKeyRevokeAction, chi.URLParam(r, "keyId"))
+	defer audit.record()
+
+	revokeRequest, err := input.NewRevokeAPIKeyRequest(r)
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	keyID := &types.APIKeyID{UUID: revokeRequest.KeyID}
+	apiKeyStore := storage.NewPostgresAPIKeyStore(db)
+
+	if err = apiKeyStore.RevokeAPIKey(keyID); err != nil {
+		if errors.Is(err, storage.ErrAPIKeyNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	audit.succeeded()
+	respondWithJSON(w, http.StatusOK, fmt.Sprintf("successfully revoked api key %s", keyID.UUID.String()))
+}
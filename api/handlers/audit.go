@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/pavbis/repositories-api/api/input"
+	"github.com/pavbis/repositories-api/application/storage"
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+// auditTrail collects the audit entry of a mutating handler, the entry is recorded when the handler returns
+// and counts as failure unless the handler marked it as succeeded
+type auditTrail struct {
+	store  storage.RecordsAuditEntries
+	logger *slog.Logger
+	entry  types.AuditEntry
+}
+
+func newAuditTrail(db storage.Executor, r *http.Request, action types.AuditAction, targets ...string) *auditTrail {
+	actor := "anonymous"
+
+	if principal, ok := PrincipalFromContext(r.Context()); ok {
+		actor = principal.Name
+	}
+
+	a := &auditTrail{
+		store:  storage.NewPostgresAuditStore(db),
+		logger: LoggerFromContext(r.Context()),
+		entry: types.AuditEntry{
+			Actor:     actor,
+			Action:    action,
+			Targets:   []string{},
+			RequestID: RequestIDFromContext(r.Context()),
+			Outcome:   types.AuditFailure,
+		},
+	}
+	a.addTargets(targets...)
+
+	return a
+}
+
+func (a *auditTrail) addTargets(targets ...string) {
+	for _, target := range targets {
+		if target != "" {
+			a.entry.Targets = append(a.entry.Targets, target)
+		}
+	}
+}
+
+func (a *auditTrail) succeeded(targets ...string) {
+	a.addTargets(targets...)
+	a.entry.Outcome = types.AuditSuccess
+}
+
+// record persists the entry, the response is already written so a failing audit store can not change it
+// and the lost entry is logged instead, the request logger adds the request id
+func (a *auditTrail) record() {
+	if err := a.store.RecordAuditEntry(&a.entry); err != nil {
+		a.logger.Error("recording audit entry failed",
+			slog.String("error", err.Error()),
+			slog.String("action", string(a.entry.Action)),
+			slog.String("actor", a.entry.Actor),
+			slog.String("outcome", string(a.entry.Outcome)),
+		)
+	}
+}
+
+// ReadAuditLogRequestHandler handles incoming request and executes storage's read audit log operation
+func ReadAuditLogRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	auditLogRequest, err := input.NewAuditLogRequest(r)
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pageRequest, err := input.NewPageRequest(r)
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	validate := newValidator()
+
+	if err = validate.Struct(auditLogRequest); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = validate.Struct(pageRequest); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if pageRequest.Cursor != nil && pageRequest.Cursor.Sort != types.AuditCursorSort {
+		respondWithError(w, http.StatusBadRequest, input.ErrCursor.Error())
+		return
+	}
+
+	auditStore := storage.NewPostgresAuditStore(db)
+	result, err := auditStore.ReadAuditLog(&types.AuditLogQuery{
+		Actor:  auditLogRequest.Actor,
+		Action: types.AuditAction(auditLogRequest.Action),
+		Since:  auditLogRequest.Since,
+		Page:   types.PageRequest{Limit: pageRequest.Limit, Cursor: pageRequest.Cursor},
+	})
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(w, http.StatusOK, result)
+}
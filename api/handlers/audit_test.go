@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+// FailingAuditStore can not record audit entries
+type FailingAuditStore struct{}
+
+func (s FailingAuditStore) RecordAuditEntry(*types.AuditEntry) error {
+	return errors.New("connection refused")
+}
+
+func TestAuditTrailLogsFailedRecording(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/languages/go", nil)
+	ctx := WithLogger(req.Context(), logger.With(slog.String("request_id", "req-1")))
+	ctx = withPrincipal(ctx, &types.Principal{Name: "importer"})
+
+	audit := newAuditTrail(nil, req.WithContext(ctx), types.LanguageImportAction, "go")
+	audit.store = FailingAuditStore{}
+	audit.succeeded()
+	audit.record()
+
+	var logged map[string]interface{}
+
+	if err := json.Unmarshal(buf.Bytes(), &logged); err != nil {
+		t.Fatalf("Expected one log line. Got %q", buf.String())
+	}
+
+	expected := map[string]interface{}{
+		"level":      "ERROR",
+		"action":     string(types.LanguageImportAction),
+		"actor":      "importer",
+		"request_id": "req-1",
+		"outcome":    string(types.AuditSuccess),
+		"error":      "connection refused",
+	}
+
+	for key, value := range expected {
+		if logged[key] != value {
+			t.Errorf("Expected %s to be %v. Got %v", key, value, logged[key])
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pavbis/repositories-api/api/input"
+	"github.com/pavbis/repositories-api/application/storage"
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+// ListBlocklistRulesRequestHandler executes storage's read blocklist rules operation
+func ListBlocklistRulesRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	blocklistStore := storage.NewPostgresBlocklistStore(db)
+	result, err := blocklistStore.ReadBlocklistRules()
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// AddBlocklistRuleRequestHandler handles incoming request and executes storage's add blocklist rule operation,
+// the stored repositories matched by the new rule are soft deleted
+func AddBlocklistRuleRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	audit := newAuditTrail(db, r, types.BlocklistAddAction)
+	defer audit.record()
+
+	ruleRequest, err := input.NewBlocklistRuleRequest(r)
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	audit.addTargets(ruleRequest.Kind + ":" + ruleRequest.Pattern)
+
+	if err = newValidator().Struct(ruleRequest); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	blocklistStore := storage.NewPostgresBlocklistStore(db)
+	result, err := blocklistStore.AddBlocklistRule(&types.BlocklistRule{
+		Kind:    types.BlocklistRuleKind(ruleRequest.Kind),
+		Pattern: ruleRequest.Pattern,
+	})
+
+	if err != nil {
+		if errors.Is(err, storage.ErrBlocklistRuleExists) {
+			respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// the repositories stored before the rule existed leave the listings as well
+	removed, err := blocklistStore.RemoveBlockedRepositories(result)
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	audit.succeeded(result.UUID.String(), "removed_repositories="+strconv.FormatInt(removed, 10))
+	respondWithJSON(w, http.StatusCreated, result)
+}
+
+// RemoveBlocklistRuleRequestHandler handles incoming request and executes storage's remove blocklist rule operation
+func RemoveBlocklistRuleRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	audit := newAuditTrail(db, r, types.BlocklistRemoveAction, chi.URLParam(r, "ruleId"))
+	defer audit.record()
+
+	removeRuleRequest, err := input.NewRemoveBlocklistRuleRequest(r)
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ruleID := &types.BlocklistRuleID{UUID: removeRuleRequest.RuleID}
+	blocklistStore := storage.NewPostgresBlocklistStore(db)
+
+	if err = blocklistStore.RemoveBlocklistRule(ruleID); err != nil {
+		if errors.Is(err, storage.ErrBlocklistRuleNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	audit.succeeded()
+	respondWithJSON(w, http.StatusOK, fmt.Sprintf("successfully deleted blocklist rule %s", ruleID.UUID.String()))
+}
@@ -3,8 +3,22 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+
+	"github.com/pavbis/repositories-api/application/types"
+
+	"github.com/go-playground/validator/v10"
 )
 
+// newValidator creates the request validator with the custom validation rules registered
+func newValidator() *validator.Validate {
+	v := validator.New()
+	_ = v.RegisterValidation("supportedLanguage", func(fl validator.FieldLevel) bool {
+		return types.SupportedProgrammingLanguageEnum(fl.Field().String()).IsValid()
+	})
+
+	return v
+}
+
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	response, _ := json.Marshal(payload)
 	respond(w, code, response)
@@ -20,7 +34,7 @@ func respond(w http.ResponseWriter, code int, data []byte) {
 	_, _ = w.Write(data)
 }
 
-// HealthRequestHandler provides response for load balancer
+// HealthRequestHandler provides response for load balancer, it only reports that the process is alive
 func HealthRequestHandler(w http.ResponseWriter, r *http.Request) {
 	status := "OK"
 
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pavbis/repositories-api/application/client"
+	"github.com/pavbis/repositories-api/application/storage"
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+const (
+	// healthCheckTimeout limits every database check, a slow database makes the api unready
+	healthCheckTimeout = 2 * time.Second
+	// readinessCacheTTL is how long the readiness result is served before the checks run again
+	readinessCacheTTL = 5 * time.Second
+)
+
+// componentHealth represents the detail of one dependency in the readiness response
+type componentHealth struct {
+	Status types.HealthStatus `json:"status"`
+	// Critical components make the api unready when they are not up
+	Critical bool                   `json:"critical"`
+	Error    string                 `json:"error,omitempty"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+}
+
+// readiness represents the readiness response
+type readiness struct {
+	Status     string                     `json:"status"`
+	Components map[string]componentHealth `json:"components"`
+}
+
+// ReadinessChecker checks the database and reports the import and GitHub state, the api is not ready when
+// the database is unreachable or the migrations are dirty or behind the expected version. The last import and
+// the GitHub rate limit are informational only, the rate limit is unknown without reporter.
+// The result is cached for a few seconds so frequent probes do not multiply the database checks.
+type ReadinessChecker struct {
+	rateLimits            client.ReportsRateLimit
+	expectedSchemaVersion uint
+	now                   func() time.Time
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	result    readiness
+}
+
+// NewReadinessChecker creates the readiness checker expecting the provided schema version
+func NewReadinessChecker(rateLimits client.ReportsRateLimit, expectedSchemaVersion uint) *ReadinessChecker {
+	return &ReadinessChecker{rateLimits: rateLimits, expectedSchemaVersion: expectedSchemaVersion, now: time.Now}
+}
+
+// ReadinessRequestHandler responds only the overall status so unauthenticated clients learn nothing
+// about the database, it responds 503 when the api is not ready
+func (c *ReadinessChecker) ReadinessRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	result := c.check(r.Context(), storage.NewPostgresHealthStore(db))
+
+	respondWithJSON(w, result.code(), map[string]string{"status": result.Status})
+}
+
+// ReadinessDetailsRequestHandler responds the status and the errors of every component, it responds 503 when the api is not ready
+func (c *ReadinessChecker) ReadinessDetailsRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	result := c.check(r.Context(), storage.NewPostgresHealthStore(db))
+
+	respondWithJSON(w, result.code(), result)
+}
+
+// check returns the cached result or runs the checks when it expired, concurrent callers wait for the running checks.
+// The checks are not cancelled with the request, a probe giving up early must not cache a failure for everyone,
+// every check is limited by the health check timeout instead
+func (c *ReadinessChecker) check(ctx context.Context, checks storage.ChecksHealth) readiness {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+
+	if c.checkedAt.IsZero() || now.Sub(c.checkedAt) >= readinessCacheTTL {
+		c.result = checkReadiness(context.WithoutCancel(ctx), checks, c.rateLimits, c.expectedSchemaVersion, now)
+		c.checkedAt = now
+	}
+
+	return c.result
+}
+
+func (r readiness) code() int {
+	if r.Status != "OK" {
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusOK
+}
+
+func checkReadiness(ctx context.Context, checks storage.ChecksHealth, rateLimits client.ReportsRateLimit, expectedSchemaVersion uint, now time.Time) readiness {
+	result := readiness{Status: "OK", Components: make(map[string]componentHealth)}
+
+	result.Components["database"] = checkDatabase(ctx, checks)
+
+	if result.Components["database"].Status == types.HealthUp {
+		result.Components["migrations"] = checkMigrations(ctx, checks, expectedSchemaVersion)
+		result.Components["last_import"] = checkLastImport(ctx, checks, now)
+	} else {
+		result.Components["migrations"] = componentHealth{Status: types.HealthUnknown, Critical: true}
+		result.Components["last_import"] = componentHealth{Status: types.HealthUnknown}
+	}
+
+	result.Components["github"] = checkGitHubRateLimit(rateLimits, now)
+
+	for _, component := range result.Components {
+		if component.Critical && component.Status != types.HealthUp {
+			result.Status = "degraded"
+		}
+	}
+
+	return result
+}
+
+func checkDatabase(ctx context.Context, checks storage.ChecksHealth) componentHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+
+	if err := checks.Ping(ctx); err != nil {
+		return componentHealth{Status: types.HealthDown, Critical: true, Error: err.Error()}
+	}
+
+	return componentHealth{
+		Status:   types.HealthUp,
+		Critical: true,
+		Details:  map[string]interface{}{"latency_ms": time.Since(start).Milliseconds()},
+	}
+}
+
+func checkMigrations(ctx context.Context, checks storage.ChecksHealth, expected uint) componentHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	v, err := checks.ReadSchemaVersion(ctx)
+
+	if err != nil {
+		return componentHealth{Status: types.HealthDown, Critical: true, Error: err.Error()}
+	}
+
+	health := componentHealth{
+		Status:   types.HealthUp,
+		Critical: true,
+		Details: map[string]interface{}{
+			"version":          v.Version,
+			"expected_version": expected,
+			"dirty":            v.Dirty,
+		},
+	}
+
+	// a newer schema is expected during a rolling deploy, the first new instance migrates while the old ones still serve
+	switch {
+	case v.Dirty:
+		health.Status, health.Error = types.HealthDown, fmt.Sprintf("migration %d failed and left the schema dirty", v.Version)
+	case v.Version < expected:
+		health.Status, health.Error = types.HealthDown, fmt.Sprintf("schema version %d, expected %d", v.Version, expected)
+	case v.Version > expected:
+		health.Details["newer_schema"] = true
+	}
+
+	return health
+}
+
+func checkLastImport(ctx context.Context, checks storage.ChecksHealth, now time.Time) componentHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	lastImport, err := checks.ReadLastSuccessfulImport(ctx)
+
+	if err != nil {
+		return componentHealth{Status: types.HealthDown, Error: err.Error()}
+	}
+
+	if lastImport.IsZero() {
+		return componentHealth{Status: types.HealthUnknown, Error: "no import succeeded yet"}
+	}
+
+	return componentHealth{
+		Status: types.HealthUp,
+		Details: map[string]interface{}{
+			"last_success": lastImport.UTC().Format(time.RFC3339),
+			"age_seconds":  int64(now.Sub(lastImport).Seconds()),
+		},
+	}
+}
+
+func checkGitHubRateLimit(rateLimits client.ReportsRateLimit, now time.Time) componentHealth {
+	if rateLimits == nil {
+		return componentHealth{Status: types.HealthUnknown}
+	}
+
+	limit, ok := rateLimits.RateLimit()
+
+	if !ok {
+		return componentHealth{Status: types.HealthUnknown, Error: "no GitHub api response yet"}
+	}
+
+	health := componentHealth{
+		Status: types.HealthUp,
+		Details: map[string]interface{}{
+			"limit":     limit.Limit,
+			"remaining": limit.Remaining,
+			"reset":     limit.Reset.UTC().Format(time.RFC3339),
+		},
+	}
+
+	if limit.Exhausted(now) {
+		health.Status, health.Error = types.HealthDown, "rate limit exhausted, imports fail until the reset"
+	}
+
+	return health
+}
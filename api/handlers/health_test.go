@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+const expectedSchemaVersion uint = 11
+
+type FakeHealthStore struct {
+	pings      *int
+	pingErr    error
+	version    types.SchemaVersion
+	lastImport time.Time
+}
+
+func (f FakeHealthStore) Ping(ctx context.Context) error {
+	if f.pings != nil {
+		*f.pings++
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return f.pingErr
+}
+
+func (f FakeHealthStore) ReadSchemaVersion(_ context.Context) (types.SchemaVersion, error) {
+	return f.version, nil
+}
+
+func (f FakeHealthStore) ReadLastSuccessfulImport(_ context.Context) (time.Time, error) {
+	return f.lastImport, nil
+}
+
+type FakeRateLimitReporter struct {
+	limit types.GitHubRateLimit
+}
+
+func (f FakeRateLimitReporter) RateLimit() (types.GitHubRateLimit, bool) {
+	return f.limit, true
+}
+
+func TestCheckReadiness(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	current := types.SchemaVersion{Version: expectedSchemaVersion}
+	exhausted := FakeRateLimitReporter{types.GitHubRateLimit{Limit: 10, Remaining: 0, Reset: now.Add(time.Minute)}}
+
+	tests := []struct {
+		name               string
+		store              FakeHealthStore
+		rateLimits         FakeRateLimitReporter
+		expectedStatus     string
+		expectedComponents map[string]types.HealthStatus
+	}{
+		{
+			name:           "Test ready",
+			store:          FakeHealthStore{version: current, lastImport: now.Add(-time.Hour)},
+			rateLimits:     FakeRateLimitReporter{types.GitHubRateLimit{Limit: 10, Remaining: 9, Reset: now.Add(time.Minute)}},
+			expectedStatus: "OK",
+			expectedComponents: map[string]types.HealthStatus{
+				"database": types.HealthUp, "migrations": types.HealthUp, "last_import": types.HealthUp, "github": types.HealthUp,
+			},
+		},
+		{
+			name:           "Test database down",
+			store:          FakeHealthStore{pingErr: errors.New("connection refused")},
+			expectedStatus: "degraded",
+			expectedComponents: map[string]types.HealthStatus{
+				"database": types.HealthDown, "migrations": types.HealthUnknown, "last_import": types.HealthUnknown,
+			},
+		},
+		{
+			name:               "Test outdated migrations",
+			store:              FakeHealthStore{version: types.SchemaVersion{Version: expectedSchemaVersion - 1}},
+			expectedStatus:     "degraded",
+			expectedComponents: map[string]types.HealthStatus{"migrations": types.HealthDown, "last_import": types.HealthUnknown},
+		},
+		{
+			name:               "Test newer migrations of a rolling deploy",
+			store:              FakeHealthStore{version: types.SchemaVersion{Version: expectedSchemaVersion + 1}},
+			expectedStatus:     "OK",
+			expectedComponents: map[string]types.HealthStatus{"migrations": types.HealthUp},
+		},
+		{
+			name:               "Test dirty migrations",
+			store:              FakeHealthStore{version: types.SchemaVersion{Version: expectedSchemaVersion, Dirty: true}},
+			expectedStatus:     "degraded",
+			expectedComponents: map[string]types.HealthStatus{"migrations": types.HealthDown},
+		},
+		{
+			name:               "Test exhausted GitHub rate limit is not critical",
+			store:              FakeHealthStore{version: current},
+			rateLimits:         exhausted,
+			expectedStatus:     "OK",
+			expectedComponents: map[string]types.HealthStatus{"github": types.HealthDown},
+		},
+	}
+
+	for _, test := range tests {
+		result := checkReadiness(context.Background(), test.store, test.rateLimits, expectedSchemaVersion, now)
+
+		if result.Status != test.expectedStatus {
+			t.Errorf("%s: expected status %s. Got %s", test.name, test.expectedStatus, result.Status)
+		}
+
+		for name, status := range test.expectedComponents {
+			if result.Components[name].Status != status {
+				t.Errorf("%s: expected component %s to be %s. Got %s", test.name, name, status, result.Components[name].Status)
+			}
+		}
+	}
+}
+
+func TestReadinessCheckerIgnoresCancelledCaller(t *testing.T) {
+	store := FakeHealthStore{version: types.SchemaVersion{Version: expectedSchemaVersion}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if result := NewReadinessChecker(nil, expectedSchemaVersion).check(ctx, store); result.Status != "OK" {
+		t.Errorf("Expected the cancelled probe not to fail the cached checks. Got %v", result.Components["database"])
+	}
+}
+
+func TestCheckReadinessReportsNewerSchema(t *testing.T) {
+	store := FakeHealthStore{version: types.SchemaVersion{Version: expectedSchemaVersion + 1}}
+	result := checkReadiness(context.Background(), store, nil, expectedSchemaVersion, time.Now())
+
+	if result.Components["migrations"].Details["newer_schema"] != true {
+		t.Errorf("Expected the newer schema in the details. Got %v", result.Components["migrations"].Details)
+	}
+}
+
+func TestCheckReadinessWithoutRateLimitReporter(t *testing.T) {
+	result := checkReadiness(context.Background(), FakeHealthStore{version: types.SchemaVersion{Version: expectedSchemaVersion}}, nil, expectedSchemaVersion, time.Now())
+
+	if result.Components["github"].Status != types.HealthUnknown {
+		t.Errorf("Expected unknown GitHub status. Got %s", result.Components["github"].Status)
+	}
+}
+
+func TestReadinessCheckerCachesResult(t *testing.T) {
+	var pings int
+	store := FakeHealthStore{pings: &pings, version: types.SchemaVersion{Version: expectedSchemaVersion}}
+
+	now := time.Now()
+	checker := NewReadinessChecker(nil, expectedSchemaVersion)
+	checker.now = func() time.Time { return now }
+
+	checker.check(context.Background(), store)
+	now = now.Add(readinessCacheTTL - time.Second)
+	checker.check(context.Background(), store)
+
+	if pings != 1 {
+		t.Errorf("Expected the cached result within the ttl. Got %d checks", pings)
+	}
+
+	now = now.Add(time.Second)
+	store.pingErr = errors.New("connection refused")
+
+	if result := checker.check(context.Background(), store); pings != 2 || result.code() != http.StatusServiceUnavailable {
+		t.Errorf("Expected the checks to run again after the ttl. Got %d checks and status %s", pings, result.Status)
+	}
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pavbis/repositories-api/application/types"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// the file is checked for changes at most this often
+const htpasswdReloadInterval = time.Second
+
+// dummyHash is compared for unknown users so they take as long as known ones
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+type htpasswdUser struct {
+	hash []byte
+	role types.Role
+}
+
+// HtpasswdAuthenticator authenticates basic auth clients against the users of an htpasswd style file.
+// Each line holds user:bcrypt-hash[:role], users without role are readers.
+// The file is reloaded when it changes, a broken file keeps the previously loaded users.
+type HtpasswdAuthenticator struct {
+	path string
+	now  func() time.Time
+
+	mu        sync.RWMutex
+	users     map[string]htpasswdUser
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// NewHtpasswdAuthenticator creates authenticator for the provided file and loads it once
+func NewHtpasswdAuthenticator(path string) (*HtpasswdAuthenticator, error) {
+	a := &HtpasswdAuthenticator{path: path, now: time.Now}
+
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.load(info.ModTime()); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Authenticate checks the basic auth header against the bcrypt hash of the user
+func (a *HtpasswdAuthenticator) Authenticate(r *http.Request) (*types.Principal, error) {
+	user, pass, ok := r.BasicAuth()
+
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	a.reloadIfChanged()
+
+	a.mu.RLock()
+	entry, found := a.users[user]
+	a.mu.RUnlock()
+
+	if !found {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(pass))
+		return nil, ErrInvalidCredentials
+	}
+
+	if bcrypt.CompareHashAndPassword(entry.hash, []byte(pass)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &types.Principal{Name: user, Roles: []types.Role{entry.role}}, nil
+}
+
+func (a *HtpasswdAuthenticator) reloadIfChanged() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+
+	if now.Sub(a.checkedAt) < htpasswdReloadInterval {
+		return
+	}
+
+	a.checkedAt = now
+	info, err := os.Stat(a.path)
+
+	if err != nil || info.ModTime().Equal(a.modTime) {
+		return
+	}
+
+	if err = a.load(info.ModTime()); err != nil {
+		slog.Warn("keeping previously loaded htpasswd users", slog.String("error", err.Error()))
+	}
+}
+
+func (a *HtpasswdAuthenticator) load(modTime time.Time) error {
+	data, err := os.ReadFile(a.path)
+
+	if err != nil {
+		return err
+	}
+
+	users, err := parseHtpasswd(data)
+
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", a.path, err)
+	}
+
+	a.users = users
+	a.modTime = modTime
+
+	return nil
+}
+
+func parseHtpasswd(data []byte) (map[string]htpasswdUser, error) {
+	users := make(map[string]htpasswdUser)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		fields := strings.Split(entry, ":")
+
+		if len(fields) < 2 || len(fields) > 3 || fields[0] == "" {
+			return nil, fmt.Errorf("line %d: expected user:hash[:role]", line)
+		}
+
+		if _, err := bcrypt.Cost([]byte(fields[1])); err != nil {
+			return nil, fmt.Errorf("line %d: only bcrypt hashes are supported", line)
+		}
+
+		role := types.ReaderRole
+
+		if len(fields) == 3 {
+			role = types.Role(fields[2])
+		}
+
+		if !role.IsValid() {
+			return nil, fmt.Errorf("line %d: invalid role %q", line, role)
+		}
+
+		users[fields[0]] = htpasswdUser{hash: []byte(fields[1]), role: role}
+	}
+
+	return users, scanner.Err()
+}
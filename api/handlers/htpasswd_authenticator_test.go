@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pavbis/repositories-api/application/types"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func htpasswdLine(t *testing.T, user, pass, role string) string {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := user + ":" + string(hash)
+
+	if role != "" {
+		line += ":" + role
+	}
+
+	return line + "\n"
+}
+
+func basicAuthRequest(user, pass string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, dummyURL, nil)
+	req.SetBasicAuth(user, pass)
+
+	return req
+}
+
+func TestHtpasswdAuthenticator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "htpasswd")
+	content := "# users\n" + htpasswdLine(t, "alice", "secret", "admin") + htpasswdLine(t, "bob", "hunter2", "")
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator, err := NewHtpasswdAuthenticator(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		user          string
+		pass          string
+		expectedRole  types.Role
+		expectedError bool
+	}{
+		{name: "Test user with role", user: "alice", pass: "secret", expectedRole: types.AdminRole},
+		{name: "Test user without role", user: "bob", pass: "hunter2", expectedRole: types.ReaderRole},
+		{name: "Test wrong password", user: "alice", pass: "hunter2", expectedError: true},
+		{name: "Test unknown user", user: "mallory", pass: "secret", expectedError: true},
+	}
+
+	for _, test := range tests {
+		principal, err := authenticator.Authenticate(basicAuthRequest(test.user, test.pass))
+
+		if (err != nil) != test.expectedError {
+			t.Errorf("for htpasswd test '%s', got error %v but expected error %t", test.name, err, test.expectedError)
+			continue
+		}
+
+		if err == nil && (principal.Name != test.user || !principal.HasRole(test.expectedRole) ||
+			(test.expectedRole == types.ReaderRole && principal.HasRole(types.ImporterRole))) {
+			t.Errorf("for htpasswd test '%s', got unexpected principal %v", test.name, principal)
+		}
+	}
+}
+
+func TestHtpasswdAuthenticatorReloadsChangedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "htpasswd")
+
+	if err := os.WriteFile(path, []byte(htpasswdLine(t, "alice", "secret", "")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator, err := NewHtpasswdAuthenticator(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	authenticator.now = func() time.Time { return now }
+
+	modTime := now.Add(time.Minute)
+	writeWithModTime := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		modTime = modTime.Add(time.Minute)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(htpasswdReloadInterval)
+	}
+
+	writeWithModTime(htpasswdLine(t, "carol", "changed", ""))
+
+	if _, err = authenticator.Authenticate(basicAuthRequest("carol", "changed")); err != nil {
+		t.Errorf("Expected added user to be accepted. Got %v", err)
+	}
+
+	if _, err = authenticator.Authenticate(basicAuthRequest("alice", "secret")); err == nil {
+		t.Error("Expected removed user to be rejected")
+	}
+
+	writeWithModTime("carol:plaintext\n")
+
+	if _, err = authenticator.Authenticate(basicAuthRequest("carol", "changed")); err != nil {
+		t.Errorf("Expected users to be kept when the file is broken. Got %v", err)
+	}
+}
+
+func TestParseHtpasswdWithInvalidEntries(t *testing.T) {
+	for _, content := range []string{"alice", "alice:plaintext", ":$2a$04$abc", "alice:$2y$04$aaaaaaaaaaaaaaaaaaaaaa:a:b"} {
+		if _, err := parseHtpasswd([]byte(content)); err == nil {
+			t.Errorf("Expected error for htpasswd content %q. Got none", content)
+		}
+	}
+
+	if _, err := parseHtpasswd([]byte(htpasswdLine(t, "alice", "secret", "guest"))); err == nil {
+		t.Error("Expected error for invalid role. Got none")
+	}
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownKey represents error in case the key set does not contain the requested key id
+var ErrUnknownKey = errors.New("unknown signing key")
+
+const (
+	defaultJWKSRefreshInterval = time.Hour
+	// unknown key ids trigger a reload at most this often so forged tokens can not flood the provider
+	minJWKSReloadInterval = time.Minute
+)
+
+// KeySet provides the public keys used to verify token signatures
+type KeySet interface {
+	Key(kid string) (crypto.PublicKey, error)
+}
+
+// JWKSKeySet caches the keys of a json web key set loaded from a file or url
+// and reloads them to pick up rotated keys
+type JWKSKeySet struct {
+	source          string
+	client          *http.Client
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	loadedAt    time.Time
+	attemptedAt time.Time
+	// reloading is closed when the running reload finished, it is nil while no reload runs
+	reloading chan struct{}
+}
+
+// NewJWKSKeySet creates key set for the provided file path or http(s) url and loads it once
+func NewJWKSKeySet(source string, refreshInterval time.Duration) (*JWKSKeySet, error) {
+	if refreshInterval <= 0 {
+		refreshInterval = defaultJWKSRefreshInterval
+	}
+
+	ks := &JWKSKeySet{
+		source:          source,
+		client:          &http.Client{Timeout: 10 * time.Second},
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+	}
+
+	keys, err := ks.load()
+
+	if err != nil {
+		return nil, err
+	}
+
+	ks.keys = keys
+	ks.loadedAt = ks.now()
+	ks.attemptedAt = ks.loadedAt
+
+	return ks, nil
+}
+
+// Key returns the key for the provided key id, the key set is reloaded when it is stale
+// or the key id is unknown. Reloads are attempted at most once a minute and run without blocking
+// the requests whose key is cached, only requests for an unknown key id wait for them.
+// On reload errors the cached keys are kept.
+func (ks *JWKSKeySet) Key(kid string) (crypto.PublicKey, error) {
+	ks.mu.Lock()
+
+	now := ks.now()
+	key, ok := ks.keys[kid]
+	due := now.Sub(ks.attemptedAt) >= minJWKSReloadInterval && (!ok || now.Sub(ks.loadedAt) >= ks.refreshInterval)
+
+	if due && ks.reloading == nil {
+		ks.reloading = make(chan struct{})
+		ks.attemptedAt = now
+
+		go ks.reload(now, ks.reloading)
+	}
+
+	reloading := ks.reloading
+	ks.mu.Unlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if reloading == nil {
+		return nil, ErrUnknownKey
+	}
+
+	<-reloading
+
+	ks.mu.Lock()
+	key, ok = ks.keys[kid]
+	ks.mu.Unlock()
+
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// reload replaces the cached keys and closes done when it finished
+func (ks *JWKSKeySet) reload(attemptedAt time.Time, done chan struct{}) {
+	defer close(done)
+
+	keys, err := ks.load()
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.reloading = nil
+
+	if err != nil {
+		slog.Warn("keeping previously loaded jwks keys", slog.String("error", err.Error()))
+		return
+	}
+
+	ks.keys = keys
+	ks.loadedAt = attemptedAt
+}
+
+func (ks *JWKSKeySet) load() (map[string]crypto.PublicKey, error) {
+	data, err := ks.read()
+
+	if err != nil {
+		return nil, fmt.Errorf("reading jwks %s: %w", ks.source, err)
+	}
+
+	keys, err := parseJWKS(data)
+
+	if err != nil {
+		return nil, fmt.Errorf("parsing jwks %s: %w", ks.source, err)
+	}
+
+	return keys, nil
+}
+
+func (ks *JWKSKeySet) read() ([]byte, error) {
+	if !strings.HasPrefix(ks.source, "http://") && !strings.HasPrefix(ks.source, "https://") {
+		return os.ReadFile(ks.source)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, ks.source, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ks.client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS parses the RSA and EC signing keys of the key set, other keys are skipped
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		var key crypto.PublicKey
+		var err error
+
+		switch jwk.Kty {
+		case "RSA":
+			key, err = jwk.rsaPublicKey()
+		case "EC":
+			key, err = jwk.ecdsaPublicKey()
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", jwk.Kid, err)
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (jwk jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+
+	if err != nil {
+		return nil, err
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+
+	if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid rsa key")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+func (jwk jsonWebKey) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+
+	switch jwk.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+
+	if err != nil {
+		return nil, err
+	}
+
+	size := (curve.Params().BitSize + 7) / 8
+
+	if len(x) != size || len(y) != size {
+		return nil, errors.New("invalid ec key coordinates")
+	}
+
+	point := append([]byte{4}, append(x, y...)...)
+
+	return ecdsa.ParseUncompressedPublicKey(curve, point)
+}
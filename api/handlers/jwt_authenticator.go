@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+const defaultRolesClaim = "roles"
+
+// JWTConfig configures the validation of the bearer tokens issued by the identity provider,
+// the tokens must carry the issuer and the audience
+type JWTConfig struct {
+	Issuer   string
+	Audience string
+	// RolesClaim names the claim holding the role or list of roles, "roles" by default
+	RolesClaim string
+	// RoleMapping maps claim values to roles, values without mapping are used if they name a role
+	RoleMapping map[string]types.Role
+	Leeway      time.Duration
+}
+
+type jwtAuthenticator struct {
+	keys   KeySet
+	config JWTConfig
+	parser *jwt.Parser
+}
+
+// NewJWTAuthenticator creates authenticator which accepts RS256 and ES256 signed bearer tokens
+func NewJWTAuthenticator(keys KeySet, config JWTConfig) Authenticator {
+	if config.RolesClaim == "" {
+		config.RolesClaim = defaultRolesClaim
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(config.Leeway),
+		jwt.WithIssuer(config.Issuer),
+		jwt.WithAudience(config.Audience),
+	}
+
+	return &jwtAuthenticator{keys: keys, config: config, parser: jwt.NewParser(options...)}
+}
+
+// Authenticate verifies the bearer token and maps its roles claim to the principal's roles
+func (a *jwtAuthenticator) Authenticate(r *http.Request) (*types.Principal, error) {
+	token := bearerToken(r)
+
+	if token == "" || strings.HasPrefix(token, types.APIKeySecretPrefix) {
+		return nil, ErrInvalidCredentials
+	}
+
+	claims := jwt.MapClaims{}
+
+	if _, err := a.parser.ParseWithClaims(token, claims, a.key); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+
+	subject, _ := claims.GetSubject()
+	roles := a.roles(claims[a.config.RolesClaim])
+
+	if subject == "" || len(roles) == 0 {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &types.Principal{Name: "jwt:" + subject, Roles: roles}, nil
+}
+
+func (a *jwtAuthenticator) key(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+
+	if !ok {
+		return nil, errors.New("missing key id")
+	}
+
+	return a.keys.Key(kid)
+}
+
+// roles accepts the claim as single string, space separated string or list of strings
+func (a *jwtAuthenticator) roles(claim interface{}) []types.Role {
+	var values []string
+
+	switch v := claim.(type) {
+	case string:
+		values = strings.Fields(v)
+	case []interface{}:
+		for _, value := range v {
+			if s, ok := value.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	var roles []types.Role
+
+	for _, value := range values {
+		role, ok := a.config.RoleMapping[value]
+
+		if !ok {
+			role = types.Role(value)
+		}
+
+		if role.IsValid() {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
+
+// ParseRoleMapping parses the comma separated list of claim-value:role entries
+func ParseRoleMapping(value string) (map[string]types.Role, error) {
+	mapping := make(map[string]types.Role)
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+
+		if entry == "" {
+			continue
+		}
+
+		sep := strings.LastIndex(entry, ":")
+
+		if sep <= 0 {
+			return nil, fmt.Errorf("invalid role mapping entry %q, expected claim-value:role", entry)
+		}
+
+		role := types.Role(entry[sep+1:])
+
+		if !role.IsValid() {
+			return nil, fmt.Errorf("invalid role %q for claim value %s", role, entry[:sep])
+		}
+
+		mapping[entry[:sep]] = role
+	}
+
+	return mapping, nil
+}
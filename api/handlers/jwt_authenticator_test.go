@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+const (
+	testIssuer   = "https://id.example.com"
+	testAudience = "repositories-api"
+)
+
+// testKeys holds the locally generated signing keys of the fake identity provider
+type testKeys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testKeys{rsa: rsaKey, ec: ecKey}
+}
+
+func encodeInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+// writeJWKS writes the public keys under the provided key ids to a key set file
+func writeJWKS(t *testing.T, path string, keys map[string]crypto.PublicKey) {
+	t.Helper()
+
+	var set struct {
+		Keys []map[string]string `json:"keys"`
+	}
+
+	for kid, key := range keys {
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, map[string]string{
+				"kty": "RSA", "kid": kid, "use": "sig", "n": encodeInt(k.N), "e": encodeInt(big.NewInt(int64(k.E))),
+			})
+		case *ecdsa.PublicKey:
+			raw, _ := k.Bytes()
+			set.Keys = append(set.Keys, map[string]string{
+				"kty": "EC", "kid": kid, "crv": "P-256",
+				"x": base64.RawURLEncoding.EncodeToString(raw[1:33]),
+				"y": base64.RawURLEncoding.EncodeToString(raw[33:]),
+			})
+		}
+	}
+
+	data, _ := json.Marshal(set)
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "jane",
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"repo-importers"},
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func bearerRequest(token string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, dummyURL, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return req
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	keys := newTestKeys(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, map[string]crypto.PublicKey{"rsa-1": &keys.rsa.PublicKey, "ec-1": &keys.ec.PublicKey})
+
+	keySet, err := NewJWKSKeySet(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator := NewJWTAuthenticator(keySet, JWTConfig{
+		Issuer:      testIssuer,
+		Audience:    testAudience,
+		RoleMapping: map[string]types.Role{"repo-importers": types.ImporterRole},
+	})
+
+	with := func(key string, value interface{}) jwt.MapClaims {
+		claims := validClaims()
+		claims[key] = value
+		return claims
+	}
+
+	tests := []struct {
+		name          string
+		token         string
+		expectedRole  types.Role
+		expectedError bool
+	}{
+		{
+			name:         "Test RS256 token",
+			token:        sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, validClaims()),
+			expectedRole: types.ImporterRole,
+		},
+		{
+			name:         "Test ES256 token",
+			token:        sign(t, jwt.SigningMethodES256, "ec-1", keys.ec, validClaims()),
+			expectedRole: types.ImporterRole,
+		},
+		{
+			name:         "Test role named in claim",
+			token:        sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, with("roles", "admin")),
+			expectedRole: types.AdminRole,
+		},
+		{
+			name:          "Test expired token",
+			token:         sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, with("exp", time.Now().Add(-time.Hour).Unix())),
+			expectedError: true,
+		},
+		{
+			name:          "Test token without expiry",
+			token:         sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, with("exp", nil)),
+			expectedError: true,
+		},
+		{
+			name:          "Test foreign issuer",
+			token:         sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, with("iss", "https://evil.example.com")),
+			expectedError: true,
+		},
+		{
+			name:          "Test foreign audience",
+			token:         sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, with("aud", "other-api")),
+			expectedError: true,
+		},
+		{
+			name:          "Test token without known role",
+			token:         sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, with("roles", []string{"unknown"})),
+			expectedError: true,
+		},
+		{
+			name:          "Test unknown key id",
+			token:         sign(t, jwt.SigningMethodRS256, "rsa-2", keys.rsa, validClaims()),
+			expectedError: true,
+		},
+		{
+			name:          "Test key of other type",
+			token:         sign(t, jwt.SigningMethodES256, "rsa-1", keys.ec, validClaims()),
+			expectedError: true,
+		},
+		{
+			name:          "Test HS256 token",
+			token:         sign(t, jwt.SigningMethodHS256, "rsa-1", []byte("secret"), validClaims()),
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		principal, err := authenticator.Authenticate(bearerRequest(test.token))
+
+		if (err != nil) != test.expectedError {
+			t.Errorf("for jwt test '%s', got error %v but expected error %t", test.name, err, test.expectedError)
+			continue
+		}
+
+		if err == nil && (principal.Name != "jwt:jane" || !principal.HasRole(test.expectedRole)) {
+			t.Errorf("for jwt test '%s', got unexpected principal %v", test.name, principal)
+		}
+	}
+}
+
+func TestJWKSKeySetPicksUpRotatedKeys(t *testing.T) {
+	keys := newTestKeys(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, map[string]crypto.PublicKey{"rsa-1": &keys.rsa.PublicKey})
+
+	keySet, err := NewJWKSKeySet(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	keySet.now = func() time.Time { return now }
+
+	writeJWKS(t, path, map[string]crypto.PublicKey{"ec-2": &keys.ec.PublicKey})
+
+	if _, err = keySet.Key("ec-2"); err == nil {
+		t.Error("Expected unknown key id not to trigger a reload right after loading")
+	}
+
+	now = now.Add(minJWKSReloadInterval)
+
+	if _, err = keySet.Key("ec-2"); err != nil {
+		t.Errorf("Expected rotated key to be loaded. Got %v", err)
+	}
+
+	if _, err = keySet.Key("rsa-1"); err == nil {
+		t.Error("Expected removed key to be rejected")
+	}
+
+	if err = os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(time.Hour)
+
+	if _, err = keySet.Key("ec-2"); err != nil {
+		t.Errorf("Expected cached keys to be kept when reload fails. Got %v", err)
+	}
+
+	waitForReload(keySet)
+}
+
+// waitForReload waits until the reload started by the key set finished
+func waitForReload(ks *JWKSKeySet) {
+	ks.mu.Lock()
+	reloading := ks.reloading
+	ks.mu.Unlock()
+
+	if reloading != nil {
+		<-reloading
+	}
+}
+
+func TestJWKSKeySetBacksOffWhenProviderIsDown(t *testing.T) {
+	keys := newTestKeys(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, map[string]crypto.PublicKey{"rsa-1": &keys.rsa.PublicKey})
+
+	var requests atomic.Int32
+	down := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) > 1 {
+			<-down
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		http.ServeFile(w, r, path)
+	}))
+	defer server.Close()
+
+	keySet, err := NewJWKSKeySet(server.URL, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().Add(time.Hour)
+	keySet.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		if _, err = keySet.Key("rsa-1"); err != nil {
+			t.Fatalf("Expected cached key to be served while the provider hangs. Got %v", err)
+		}
+	}
+
+	close(down)
+	waitForReload(keySet)
+
+	for i := 0; i < 5; i++ {
+		if _, err = keySet.Key("rsa-1"); err != nil {
+			t.Fatalf("Expected cached key to be served after the failed reload. Got %v", err)
+		}
+	}
+
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected one reload attempt within the backoff. Got %d requests", got-1)
+	}
+
+	now = now.Add(minJWKSReloadInterval)
+
+	if _, err = keySet.Key("rsa-1"); err != nil {
+		t.Fatalf("Expected cached key. Got %v", err)
+	}
+
+	waitForReload(keySet)
+
+	if got := requests.Load(); got != 3 {
+		t.Errorf("Expected the reload to be retried after the backoff. Got %d requests", got-1)
+	}
+}
+
+func TestParseRoleMapping(t *testing.T) {
+	mapping, err := ParseRoleMapping("repo-readers:reader, platform:admins:admin")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mapping["repo-readers"] != types.ReaderRole || mapping["platform:admins"] != types.AdminRole {
+		t.Errorf("got unexpected role mapping %v", mapping)
+	}
+
+	if _, err = ParseRoleMapping("repo-readers:superuser"); err == nil {
+		t.Error("Expected invalid role to be rejected")
+	}
+}
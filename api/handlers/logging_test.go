@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name             string
+		requestID        string
+		expectedAccepted bool
+	}{
+		{name: "Test provided request id", requestID: "abc-123", expectedAccepted: true},
+		{name: "Test missing request id", requestID: "", expectedAccepted: false},
+		{name: "Test request id with spaces", requestID: "abc 123", expectedAccepted: false},
+		{name: "Test too long request id", requestID: strings.Repeat("a", maxRequestIDLength+1), expectedAccepted: false},
+	}
+
+	for _, test := range tests {
+		var contextRequestID string
+		handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contextRequestID = RequestIDFromContext(r.Context())
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, dummyURL, nil)
+		req.Header.Set(RequestIDHeader, test.requestID)
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+
+		responseRequestID := res.Header().Get(RequestIDHeader)
+
+		if responseRequestID == "" || responseRequestID != contextRequestID {
+			t.Errorf("for request id test '%s', got response id %q and context id %q", test.name, responseRequestID, contextRequestID)
+		}
+
+		if (responseRequestID == test.requestID) != test.expectedAccepted {
+			t.Errorf("for request id test '%s', got id %q but expected accepted %t", test.name, responseRequestID, test.expectedAccepted)
+		}
+	}
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	router := chi.NewRouter()
+	router.Use(RequestIDMiddleware, AccessLogMiddleware(logger))
+	router.Get("/api/owners/{login}", func(w http.ResponseWriter, r *http.Request) {
+		LoggerFromContext(r.Context()).Info("handler")
+		respondWithError(w, http.StatusNotFound, "owner not found")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/owners/golang", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	if len(lines) != 2 {
+		t.Fatalf("Expected handler and access log lines. Got %q", buf.String())
+	}
+
+	var handlerLog, accessLog map[string]interface{}
+	_ = json.Unmarshal([]byte(lines[0]), &handlerLog)
+	_ = json.Unmarshal([]byte(lines[1]), &accessLog)
+
+	if handlerLog["request_id"] != "req-1" {
+		t.Errorf("Expected handler log to carry the request id. Got %v", handlerLog)
+	}
+
+	expected := map[string]interface{}{
+		"msg":        "request",
+		"request_id": "req-1",
+		"method":     http.MethodGet,
+		"route":      "/api/owners/{login}",
+		"path":       "/api/owners/golang",
+		"status":     float64(http.StatusNotFound),
+	}
+
+	for key, value := range expected {
+		if accessLog[key] != value {
+			t.Errorf("Expected access log %s is %v. Got %v", key, value, accessLog[key])
+		}
+	}
+
+	if _, ok := accessLog["latency_ms"]; !ok {
+		t.Error("Expected access log to carry the latency")
+	}
+}
@@ -1,24 +1,184 @@
 package handlers
 
 import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
+
+	"github.com/pavbis/repositories-api/application/types"
 )
 
-func BasicAuthMiddleware(user, pass string, next http.HandlerFunc) http.HandlerFunc {
+// ErrInvalidCredentials represents error in case the provided credentials are not accepted
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+const (
+	// basicAuthChallenge asks the client for basic auth credentials
+	basicAuthChallenge = `Basic realm="repositories-api", charset="UTF-8"`
+	// invalidTokenChallenge tells the client the presented bearer token was rejected
+	invalidTokenChallenge = `Bearer error="invalid_token"`
+)
+
+// Authenticator authenticates the client of the request
+type Authenticator interface {
+	Authenticate(r *http.Request) (*types.Principal, error)
+}
+
+// Credentials represents the basic auth user and the role granted to it
+type Credentials struct {
+	User string
+	Pass string
+	Role types.Role
+}
+
+type basicAuthenticator struct {
+	credentials []Credentials
+}
+
+// NewBasicAuthenticator creates authenticator which accepts the provided basic auth credentials
+func NewBasicAuthenticator(credentials ...Credentials) Authenticator {
+	return &basicAuthenticator{credentials: credentials}
+}
+
+// Authenticate checks the basic auth header against the configured credentials
+// all credentials are compared so the response time does not reveal which user matched
+func (a *basicAuthenticator) Authenticate(r *http.Request) (*types.Principal, error) {
+	var principal *types.Principal
+
+	for _, c := range a.credentials {
+		if c.User != "" && checkBasicAuth(r, c.User, c.Pass) && principal == nil {
+			principal = &types.Principal{Name: c.User, Roles: []types.Role{c.Role}}
+		}
+	}
+
+	if principal == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return principal, nil
+}
+
+// ParseCredentials parses the comma separated list of user:password:role entries,
+// passwords may contain colons but no commas
+func ParseCredentials(value string) ([]Credentials, error) {
+	var credentials []Credentials
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+
+		if entry == "" {
+			continue
+		}
+
+		userEnd := strings.Index(entry, ":")
+		roleStart := strings.LastIndex(entry, ":")
+
+		if userEnd <= 0 || roleStart == userEnd {
+			return nil, fmt.Errorf("invalid credentials entry %q, expected user:password:role", entry)
+		}
+
+		role := types.Role(entry[roleStart+1:])
+
+		if !role.IsValid() {
+			return nil, fmt.Errorf("invalid role %q for user %s", role, entry[:userEnd])
+		}
+
+		credentials = append(credentials, Credentials{User: entry[:userEnd], Pass: entry[userEnd+1 : roleStart], Role: role})
+	}
+
+	return credentials, nil
+}
+
+type principalContextKey struct{}
+
+func withPrincipal(ctx context.Context, principal *types.Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal authenticated for the request
+func PrincipalFromContext(ctx context.Context) (*types.Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*types.Principal)
+
+	return principal, ok
+}
+
+// AuthenticationMiddleware rejects the request if the client can not be authenticated,
+// failures other than rejected credentials like a database outage are reported as server errors
+func AuthenticationMiddleware(authenticator Authenticator, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if checkBasicAuth(r, user, pass) {
-			next(w, r)
+		principal, err := authenticator.Authenticate(r)
+
+		if err != nil && !errors.Is(err, ErrInvalidCredentials) {
+			LoggerFromContext(r.Context()).Error("authentication failed", slog.String("error", err.Error()))
+			respondWithError(w, http.StatusInternalServerError, "authentication unavailable")
 			return
 		}
 
-		respond(w, http.StatusUnauthorized, []byte("Unauthorized"))
+		if err != nil {
+			respondUnauthorized(w, r)
+			return
+		}
+
+		next(w, r.WithContext(withPrincipal(r.Context(), principal)))
 	}
 }
 
+// RequireRoleMiddleware rejects the request if the authenticated principal lacks the required role
+func RequireRoleMiddleware(role types.Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := PrincipalFromContext(r.Context())
+
+		if !ok {
+			respondUnauthorized(w, r)
+			return
+		}
+
+		if !principal.HasRole(role) {
+			respondWithError(w, http.StatusForbidden, fmt.Sprintf("role %s required", role))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// DeprecatedRouteMiddleware marks the response of a route which is kept for backwards compatibility only
+func DeprecatedRouteMiddleware(hint string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Warning", `299 - "`+hint+`"`)
+		next(w, r)
+	}
+}
+
+// respondUnauthorized challenges the client with the scheme of the rejected credentials, basic auth by default
+func respondUnauthorized(w http.ResponseWriter, r *http.Request) {
+	challenge := basicAuthChallenge
+
+	if bearerToken(r) != "" {
+		challenge = invalidTokenChallenge
+	}
+
+	w.Header().Set("WWW-Authenticate", challenge)
+	respondWithError(w, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+}
+
 func checkBasicAuth(r *http.Request, user, pass string) bool {
 	u, p, ok := r.BasicAuth()
 	if !ok {
 		return false
 	}
-	return u == user && p == pass
+	return secureCompare(u, user)&secureCompare(p, pass) == 1
+}
+
+// secureCompare compares the digests so neither content nor length leak through timing
+func secureCompare(given, expected string) int {
+	givenSum := sha256.Sum256([]byte(given))
+	expectedSum := sha256.Sum256([]byte(expected))
+
+	return subtle.ConstantTimeCompare(givenSum[:], expectedSum[:])
 }
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pavbis/repositories-api/api/input"
+	"github.com/pavbis/repositories-api/application/storage"
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+// ReadOwnerRequestHandler executes storage's read owner operation
+func ReadOwnerRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	ownerRequest := input.NewOwnerRequest(r)
+
+	if err := newValidator().Struct(ownerRequest); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	readStore := storage.NewPostgresReadStore(db)
+	result, err := readStore.ReadOwner(&types.Owner{Login: ownerRequest.Login})
+
+	if err != nil {
+		if errors.Is(err, storage.ErrOwnerNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(w, http.StatusOK, result)
+}
+
+// TopOwnersRequestHandler executes storage's read top owners operation
+func TopOwnersRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	topOwnersRequest := input.NewTopOwnersRequest(r)
+
+	if err := newValidator().Struct(topOwnersRequest); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	readStore := storage.NewPostgresReadStore(db)
+	result, err := readStore.ReadTopOwners(&types.ProgrammingLanguage{Name: topOwnersRequest.LanguageName})
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(w, http.StatusOK, result)
+}
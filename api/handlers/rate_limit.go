@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pavbis/repositories-api/application/storage"
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+const (
+	// defaultRateLimitRoute names the limit of the routes without own limit, those routes share one bucket per client
+	defaultRateLimitRoute = "default"
+	// authenticationRateLimitRoute names the limit of the requests per remote address taken before the authentication
+	authenticationRateLimitRoute = "authentication"
+)
+
+// RateLimits holds the limit per route, routes are named by method and pattern like "GET /api/languages"
+type RateLimits map[string]types.RateLimit
+
+// ParseRateLimits parses the comma separated list of route=requests/period entries
+func ParseRateLimits(value string) (RateLimits, error) {
+	limits := make(RateLimits)
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+
+		if entry == "" {
+			continue
+		}
+
+		route, limit, ok := strings.Cut(entry, "=")
+
+		if !ok || strings.TrimSpace(route) == "" {
+			return nil, fmt.Errorf("invalid rate limit entry %q, expected route=requests/period", entry)
+		}
+
+		rateLimit, err := types.ParseRateLimit(limit)
+
+		if err != nil {
+			return nil, err
+		}
+
+		limits[strings.TrimSpace(route)] = rateLimit
+	}
+
+	return limits, nil
+}
+
+// RateLimitMiddleware rejects the requests of clients which exceeded the limit of the route,
+// clients are identified by the authenticated principal or the remote address
+func RateLimitMiddleware(limiter storage.LimitsRate, limits RateLimits, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		route := defaultRateLimitRoute
+
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			route = r.Method + " " + rctx.RoutePattern()
+		}
+
+		limit, ok := limits[route]
+
+		if !ok {
+			route = defaultRateLimitRoute
+			limit = limits[defaultRateLimitRoute]
+		}
+
+		if takeToken(w, limiter, route+"|"+clientKey(r), limit) {
+			next(w, r)
+		}
+	}
+}
+
+// AuthenticationRateLimitMiddleware rejects the requests of remote addresses which exceeded the authentication limit
+// before their credentials are checked, so guessed credentials are throttled although they never yield a principal
+func AuthenticationRateLimitMiddleware(limiter storage.LimitsRate, limits RateLimits, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if takeToken(w, limiter, authenticationRateLimitRoute+"|ip:"+remoteHost(r), limits[authenticationRateLimitRoute]) {
+			next(w, r)
+		}
+	}
+}
+
+// takeToken takes a token of the bucket and reports whether the request may proceed,
+// the rejected requests are answered with the time to retry
+func takeToken(w http.ResponseWriter, limiter storage.LimitsRate, key string, limit types.RateLimit) bool {
+	if limit.IsUnlimited() {
+		return true
+	}
+
+	decision, err := limiter.TakeToken(key, limit)
+
+	// an unavailable limiter must not take the api down with it
+	if err != nil {
+		return true
+	}
+
+	w.Header().Set("X-RateLimit-Limit", strconv.FormatInt(decision.Limit, 10))
+	w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(decision.Remaining, 10))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(seconds(decision.Reset), 10))
+
+	if !decision.Allowed {
+		w.Header().Set("Retry-After", strconv.FormatInt(seconds(decision.RetryAfter), 10))
+		respondWithError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return false
+	}
+
+	return true
+}
+
+func clientKey(r *http.Request) string {
+	if principal, ok := PrincipalFromContext(r.Context()); ok {
+		return "principal:" + principal.Name
+	}
+
+	return "ip:" + remoteHost(r)
+}
+
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func seconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
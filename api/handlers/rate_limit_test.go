@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pavbis/repositories-api/application/storage"
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+// LimiterWhichReturnsError simulates unavailable rate limit storage
+type LimiterWhichReturnsError struct{}
+
+func (l *LimiterWhichReturnsError) TakeToken(key string, limit types.RateLimit) (types.RateLimitDecision, error) {
+	return types.RateLimitDecision{}, errors.New("storage error")
+}
+
+func rateLimitedRouter(limiter storage.LimitsRate, limits RateLimits) *chi.Mux {
+	router := chi.NewRouter()
+	nextMiddleware := func(w http.ResponseWriter, r *http.Request) {}
+
+	router.Get("/api/languages", RateLimitMiddleware(limiter, limits, nextMiddleware))
+	router.Get("/api/topics", RateLimitMiddleware(limiter, limits, nextMiddleware))
+	router.Get("/api/owners/{login}", RateLimitMiddleware(limiter, limits, nextMiddleware))
+
+	return router
+}
+
+func requestFrom(remoteAddr, path string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remoteAddr
+
+	return req
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	router := rateLimitedRouter(storage.NewInMemoryRateLimitStore(), RateLimits{
+		"default":            {Requests: 2, Period: time.Minute},
+		"GET /api/languages": {Requests: 1, Period: time.Minute},
+	})
+
+	tests := []struct {
+		name                 string
+		req                  *http.Request
+		expectedResponseCode int
+		expectedRemaining    string
+	}{
+		{
+			name:                 "Test first request of route limit",
+			req:                  requestFrom("10.0.0.1:1234", "/api/languages"),
+			expectedResponseCode: http.StatusOK,
+			expectedRemaining:    "0",
+		},
+		{
+			name:                 "Test exceeded route limit",
+			req:                  requestFrom("10.0.0.1:1234", "/api/languages"),
+			expectedResponseCode: http.StatusTooManyRequests,
+			expectedRemaining:    "0",
+		},
+		{
+			name:                 "Test other client",
+			req:                  requestFrom("10.0.0.2:1234", "/api/languages"),
+			expectedResponseCode: http.StatusOK,
+			expectedRemaining:    "0",
+		},
+		{
+			name:                 "Test default limit is separate from route limit",
+			req:                  requestFrom("10.0.0.1:1234", "/api/topics"),
+			expectedResponseCode: http.StatusOK,
+			expectedRemaining:    "1",
+		},
+		{
+			name:                 "Test routes without limit share the default bucket",
+			req:                  requestFrom("10.0.0.1:1234", "/api/owners/golang"),
+			expectedResponseCode: http.StatusOK,
+			expectedRemaining:    "0",
+		},
+		{
+			name:                 "Test exceeded default limit",
+			req:                  requestFrom("10.0.0.1:1234", "/api/topics"),
+			expectedResponseCode: http.StatusTooManyRequests,
+			expectedRemaining:    "0",
+		},
+	}
+
+	for _, test := range tests {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, test.req)
+
+		if res.Code != test.expectedResponseCode {
+			t.Errorf("for rate limit test '%s', expected response code is %d. Got %d", test.name, test.expectedResponseCode, res.Code)
+		}
+
+		if res.Header().Get("X-RateLimit-Remaining") != test.expectedRemaining {
+			t.Errorf("for rate limit test '%s', expected remaining is %s. Got %s",
+				test.name, test.expectedRemaining, res.Header().Get("X-RateLimit-Remaining"))
+		}
+
+		if res.Code == http.StatusTooManyRequests && res.Header().Get("Retry-After") == "" {
+			t.Errorf("for rate limit test '%s', expected retry after header", test.name)
+		}
+	}
+}
+
+func TestRateLimitMiddlewareKeysByPrincipal(t *testing.T) {
+	router := rateLimitedRouter(storage.NewInMemoryRateLimitStore(), RateLimits{
+		"default": {Requests: 1, Period: time.Minute},
+	})
+
+	for _, remoteAddr := range []string{"10.0.0.1:1234", "10.0.0.2:1234"} {
+		req := requestFrom(remoteAddr, "/api/topics")
+		req = req.WithContext(withPrincipal(req.Context(), &types.Principal{Name: "ci"}))
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		if remoteAddr == "10.0.0.2:1234" && res.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected principal to be limited across addresses. Got %d", res.Code)
+		}
+	}
+}
+
+func TestAuthenticationRateLimitMiddlewareLimitsFailedAuthentication(t *testing.T) {
+	limiter := storage.NewInMemoryRateLimitStore()
+	limits := RateLimits{
+		"authentication": {Requests: 3, Period: time.Minute},
+		"default":        {Requests: 100, Period: time.Minute},
+	}
+	handler := AuthenticationRateLimitMiddleware(limiter, limits,
+		AuthenticationMiddleware(testAuthenticator(),
+			RateLimitMiddleware(limiter, limits, func(w http.ResponseWriter, r *http.Request) {})))
+
+	var codes []int
+
+	for i := 0; i < 4; i++ {
+		req := requestFrom("10.0.0.1:1234", dummyURL)
+		req.SetBasicAuth(testUser, "guessed")
+		res := httptest.NewRecorder()
+		handler(res, req)
+		codes = append(codes, res.Code)
+	}
+
+	expected := []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests}
+
+	for i := range expected {
+		if codes[i] != expected[i] {
+			t.Fatalf("Expected guessed credentials to be limited. Got response codes %v", codes)
+		}
+	}
+
+	req := requestFrom("10.0.0.2:1234", dummyURL)
+	req.SetBasicAuth(testUser, testPass)
+	res := httptest.NewRecorder()
+	handler(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Errorf("Expected other address not to be limited. Got %d", res.Code)
+	}
+}
+
+func TestRateLimitMiddlewareWithoutLimitOrLimiter(t *testing.T) {
+	for name, router := range map[string]*chi.Mux{
+		"unlimited":     rateLimitedRouter(storage.NewInMemoryRateLimitStore(), RateLimits{}),
+		"limiter error": rateLimitedRouter(&LimiterWhichReturnsError{}, RateLimits{"default": {Requests: 1, Period: time.Minute}}),
+	} {
+		for i := 0; i < 3; i++ {
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, requestFrom("10.0.0.1:1234", "/api/topics"))
+
+			if res.Code != http.StatusOK {
+				t.Errorf("for %s router, expected response code is %d. Got %d", name, http.StatusOK, res.Code)
+			}
+		}
+	}
+}
+
+func TestParseRateLimits(t *testing.T) {
+	limits, err := ParseRateLimits("default=100/1m, GET /api/languages=5/1s")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if limits["default"].Requests != 100 || limits["GET /api/languages"].Period != time.Second {
+		t.Errorf("got unexpected rate limits %v", limits)
+	}
+
+	for _, value := range []string{"default", "=1/1m", "default=1"} {
+		if _, err = ParseRateLimits(value); err == nil {
+			t.Errorf("Expected error for rate limits %q. Got none", value)
+		}
+	}
+}
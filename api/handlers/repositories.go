@@ -1,69 +1,180 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/pavbis/repositories-api/api/input"
 	"github.com/pavbis/repositories-api/application/client"
 	"github.com/pavbis/repositories-api/application/storage"
 	"github.com/pavbis/repositories-api/application/types"
 	"github.com/pavbis/repositories-api/application/writemodel"
-
-	"github.com/go-playground/validator/v10"
 )
 
-// ReceiveRepositoriesRequestHandler handles incoming request and executes storage's write operation
-func ReceiveRepositoriesRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
-	receiveRepositoriesRequest := input.NewLanguageRepositoriesRequest(r)
+// ImportObserver is notified about every finished import, successful or not
+type ImportObserver interface {
+	ObserveImport(language string, result types.ImportResult, duration time.Duration, err error)
+}
 
-	v := validator.New()
-	_ = v.RegisterValidation("supportedLanguage", func(fl validator.FieldLevel) bool {
-		return types.SupportedProgrammingLanguageEnum(fl.Field().String()).IsValid()
-	})
+// WriteRecorder remembers a successful import so the reads following it observe the imported repositories
+type WriteRecorder interface {
+	RecordWrite(ctx context.Context)
+}
+
+// NewReceiveRepositoriesRequestHandler creates handler which imports the repositories with the provided GitHub client,
+// the handler handles incoming request and executes storage's write operation, the observer and the recorder may be nil
+func NewReceiveRepositoriesRequestHandler(httpClient client.HTTPClient, observer ImportObserver, writes WriteRecorder) func(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	return func(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+		receiveRepositories(httpClient, observer, writes, db, w, r)
+	}
+}
 
-	if err := v.Struct(receiveRepositoriesRequest); err != nil {
+func receiveRepositories(httpClient client.HTTPClient, observer ImportObserver, writes WriteRecorder, db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	receiveRepositoriesRequest := input.NewLanguageRepositoriesRequest(r)
+	audit := newAuditTrail(db, r, types.LanguageImportAction, receiveRepositoriesRequest.LanguageName)
+	defer audit.record()
+
+	if err := newValidator().Struct(receiveRepositoriesRequest); err != nil {
 		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	httpClient := client.NewRealHTTPClient()
 	writeStorage := storage.NewPostgresWriteStore(db)
+	blocklistStorage := storage.NewPostgresBlocklistStore(db)
 	pl := &types.ProgrammingLanguage{Name: receiveRepositoriesRequest.LanguageName}
-	commandHandler := writemodel.NewWriteLanguageRepositoriesCommandHandler(httpClient, writeStorage)
+	commandHandler := writemodel.NewWriteLanguageRepositoriesCommandHandler(httpClient, writeStorage, blocklistStorage)
+
+	start := time.Now()
+	result, err := commandHandler.HandleRepositories(r.Context(), pl)
+	duration := time.Since(start)
+	if observer != nil {
+		observer.ObserveImport(pl.Name, result, duration, err)
+	}
 
-	result, err := commandHandler.HandleRepositories(pl)
+	logger := LoggerFromContext(r.Context()).With(
+		slog.String("language", pl.Name),
+		slog.Int("pages", result.Pages),
+		slog.Int("fetched", result.FetchedRepositories),
+		slog.Int("persisted", result.PersistedRepositories),
+		slog.Int("filtered", result.FilteredRepositories),
+		slog.Duration("duration", duration),
+	)
 
 	if err != nil {
+		logger.Error("import failed", slog.String("error", err.Error()))
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	logger.Info("import finished", slog.String("language_id", result.UUID.String()))
+
+	audit.succeeded(result.UUID.String())
+
+	// recorded before responding so the client's next read already waits for the replica
+	if writes != nil {
+		writes.RecordWrite(r.Context())
+	}
+
 	respondWithJSON(
 		w,
 		http.StatusCreated,
-		fmt.Sprintf("Language %s was successfully created with id %s", pl.Name, result))
+		fmt.Sprintf(
+			"Language %s was successfully created with id %s, %d repositories persisted, %d filtered by blocklist",
+			pl.Name, result.UUID.String(), result.PersistedRepositories, result.FilteredRepositories))
+}
+
+// RemoveLanguageRequestHandler handles incoming request and executes storage's remove language operation
+func RemoveLanguageRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	audit := newAuditTrail(db, r, types.LanguageDeleteAction, chi.URLParam(r, "languageName"))
+	defer audit.record()
+
+	removeLanguageRequest, err := input.NewRemoveLanguageRequest(r)
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = newValidator().Struct(removeLanguageRequest); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeStorage := storage.NewPostgresWriteStore(db)
+	pl := &types.ProgrammingLanguage{Name: removeLanguageRequest.LanguageName}
+	result, err := writeStorage.RemoveProgrammingLanguage(pl, removeLanguageRequest.KeepHistory)
+
+	if err != nil {
+		if errors.Is(err, storage.ErrLanguageNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	audit.succeeded()
+	respondWithJSON(w, http.StatusOK, result)
 }
 
 // ReadRepositoriesRequestHandler handles incoming request and executes storage's read operation
 func ReadRepositoriesRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
 	readRepositoriesRequest := input.NewLanguageRepositoriesRequest(r)
 
-	v := validator.New()
-	// add custom validation rule
-	_ = v.RegisterValidation("supportedLanguage", func(fl validator.FieldLevel) bool {
-		return types.SupportedProgrammingLanguageEnum(fl.Field().String()).IsValid()
-	})
+	if err := newValidator().Struct(readRepositoriesRequest); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pageRequest, err := input.NewPageRequest(r)
 
-	if err := v.Struct(readRepositoriesRequest); err != nil {
+	if err != nil {
 		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
+	if err = newValidator().Struct(pageRequest); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filterRequest, err := input.NewRepositoryFilterRequest(r)
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = newValidator().Struct(filterRequest); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := &types.RepositoryListQuery{
+		MinStars:      filterRequest.MinStars,
+		MaxStars:      filterRequest.MaxStars,
+		Owner:         filterRequest.Owner,
+		CreatedAfter:  filterRequest.CreatedAfter,
+		CreatedBefore: filterRequest.CreatedBefore,
+		Sort:          types.RepositorySortField(filterRequest.Sort),
+		Order:         types.SortOrder(filterRequest.Order),
+		Page:          types.PageRequest{Limit: pageRequest.Limit, Cursor: pageRequest.Cursor},
+	}
+
+	// the cursor holds the value of the sort column, it can not be used to continue a differently sorted list
+	if query.Page.Cursor != nil && query.Page.Cursor.Sort != query.SortKey() {
+		respondWithError(w, http.StatusBadRequest, input.ErrCursor.Error())
+		return
+	}
+
 	readStorage := storage.NewPostgresReadStore(db)
 	pl := &types.ProgrammingLanguage{Name: readRepositoriesRequest.LanguageName}
-	result, err := readStorage.ReadRepositoriesForLanguage(pl)
+	result, err := readStorage.ReadRepositoriesForLanguage(pl, query)
 
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
@@ -75,6 +186,9 @@ func ReadRepositoriesRequestHandler(db storage.Executor, w http.ResponseWriter,
 
 // RemoveRepositoryRequestHandler handles incoming request and executes storage's remove operation
 func RemoveRepositoryRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	audit := newAuditTrail(db, r, types.RepositoryDeleteAction, chi.URLParam(r, "repositoryId"))
+	defer audit.record()
+
 	removeRepoRequest, err := input.NewRemoveRepositoryRequest(r)
 
 	if err != nil {
@@ -95,7 +209,36 @@ func RemoveRepositoryRequestHandler(db storage.Executor, w http.ResponseWriter,
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, fmt.Sprintf("succesfully deleted repository %s", result.UUID.String()))
+	audit.succeeded()
+	respondWithJSON(w, http.StatusOK, fmt.Sprintf("successfully deleted repository %s", result.UUID.String()))
+}
+
+// RestoreRepositoryRequestHandler handles incoming request and executes storage's restore operation
+func RestoreRepositoryRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	audit := newAuditTrail(db, r, types.RepositoryRestoreAction, chi.URLParam(r, "repositoryId"))
+	defer audit.record()
+
+	restoreRepoRequest, err := input.NewRepositoryRequest(r)
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeStorage := storage.NewPostgresWriteStore(db)
+	result, err := writeStorage.RestoreRepository(&types.RepositoryID{UUID: restoreRepoRequest.RepositoryID})
+
+	if err != nil {
+		if errors.Is(err, storage.ErrDeletedRepoNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	audit.succeeded()
+	respondWithJSON(w, http.StatusOK, fmt.Sprintf("successfully restored repository %s", result.UUID.String()))
 }
 
 // TopRepositoryForLanguageRequestHandler executes storage's read top list operation
@@ -124,10 +267,22 @@ func CountRepositoriesStarsForLanguagesRequestHandler(db storage.Executor, w htt
 	respond(w, http.StatusOK, result)
 }
 
-// ListLanguagesAndRepositoriesRequestHandler executes storage's count repositories operation
+// ListLanguagesAndRepositoriesRequestHandler executes storage's paginated languages and repositories operation
 func ListLanguagesAndRepositoriesRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	pageRequest, err := input.NewPageRequest(r)
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = newValidator().Struct(pageRequest); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	readStore := storage.NewPostgresReadStore(db)
-	result, err := readStore.ReadRepositoriesAndLanguages()
+	result, err := readStore.ReadRepositoriesAndLanguages(&types.PageRequest{Limit: pageRequest.Limit, Cursor: pageRequest.Cursor})
 
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
@@ -136,3 +291,49 @@ func ListLanguagesAndRepositoriesRequestHandler(db storage.Executor, w http.Resp
 
 	respond(w, http.StatusOK, result)
 }
+
+// ReadRepositoryRequestHandler executes storage's read repository by id operation
+func ReadRepositoryRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	repositoryRequest, err := input.NewRepositoryRequest(r)
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	readStore := storage.NewPostgresReadStore(db)
+	result, err := readStore.ReadRepository(&types.RepositoryID{UUID: repositoryRequest.RepositoryID})
+
+	respondWithRepository(w, result, err)
+}
+
+// ReadRepositoryByNameRequestHandler executes storage's read repository by name operation
+func ReadRepositoryByNameRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	repositoryNameRequest := input.NewRepositoryNameRequest(r)
+
+	if err := newValidator().Struct(repositoryNameRequest); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	readStore := storage.NewPostgresReadStore(db)
+	result, err := readStore.ReadRepositoryByName(&types.RepositoryName{
+		Owner: repositoryNameRequest.Owner,
+		Name:  repositoryNameRequest.Name,
+	})
+
+	respondWithRepository(w, result, err)
+}
+
+func respondWithRepository(w http.ResponseWriter, result []byte, err error) {
+	if err != nil {
+		if errors.Is(err, storage.ErrRepoNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(w, http.StatusOK, result)
+}
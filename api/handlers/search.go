@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/pavbis/repositories-api/api/input"
+	"github.com/pavbis/repositories-api/application/storage"
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+// SearchRepositoriesRequestHandler executes storage's full-text search operation
+func SearchRepositoriesRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	searchRequest, err := input.NewSearchRepositoriesRequest(r)
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = newValidator().Struct(searchRequest); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	readStore := storage.NewPostgresReadStore(db)
+	result, err := readStore.SearchRepositories(&types.SearchQuery{
+		Text:         searchRequest.Query,
+		LanguageName: searchRequest.LanguageName,
+		Limit:        searchRequest.Limit,
+	})
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(w, http.StatusOK, result)
+}
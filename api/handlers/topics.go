@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/pavbis/repositories-api/api/input"
+	"github.com/pavbis/repositories-api/application/storage"
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+// ListTopicsRequestHandler executes storage's read topics operation
+func ListTopicsRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	readStore := storage.NewPostgresReadStore(db)
+	result, err := readStore.ReadTopics()
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(w, http.StatusOK, result)
+}
+
+// ReadTopicRepositoriesRequestHandler executes storage's read repositories by topic operation
+func ReadTopicRepositoriesRequestHandler(db storage.Executor, w http.ResponseWriter, r *http.Request) {
+	topicRepositoriesRequest := input.NewTopicRepositoriesRequest(r)
+
+	if err := newValidator().Struct(topicRepositoriesRequest); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	readStore := storage.NewPostgresReadStore(db)
+	result, err := readStore.ReadRepositoriesForTopic(
+		&types.Topic{Name: topicRepositoriesRequest.Topic},
+		&types.ProgrammingLanguage{Name: topicRepositoriesRequest.LanguageName})
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respond(w, http.StatusOK, result)
+}
@@ -0,0 +1,45 @@
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+var ErrAPIKeyID = errors.New("missing or invalid api key id provided")
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=reader importer admin"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty,gt"`
+}
+
+// NewCreateAPIKeyRequest creates api key input from the json request body, the key never expires by default
+func NewCreateAPIKeyRequest(r *http.Request) (*CreateAPIKeyRequest, error) {
+	var apiKeyRequest CreateAPIKeyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&apiKeyRequest); err != nil {
+		return nil, ErrRequestBody
+	}
+
+	return &apiKeyRequest, nil
+}
+
+type RevokeAPIKeyRequest struct {
+	KeyID uuid.UUID
+}
+
+// NewRevokeAPIKeyRequest creates revoke api key input from the key id url parameter
+func NewRevokeAPIKeyRequest(r *http.Request) (*RevokeAPIKeyRequest, error) {
+	keyID, err := uuid.Parse(chi.URLParam(r, "keyId"))
+
+	if err != nil {
+		return nil, ErrAPIKeyID
+	}
+
+	return &RevokeAPIKeyRequest{KeyID: keyID}, nil
+}
@@ -0,0 +1,24 @@
+package input
+
+import (
+	"net/http"
+	"time"
+)
+
+type AuditLogRequest struct {
+	Actor  string `validate:"omitempty,max=200"`
+	Action string `validate:"omitempty,oneof=language.import language.delete repository.delete repository.restore blocklist.add blocklist.remove api_key.create api_key.revoke data.prune"`
+	Since  *time.Time
+}
+
+// NewAuditLogRequest creates audit log filter input from the actor, action and since query parameters
+func NewAuditLogRequest(r *http.Request) (*AuditLogRequest, error) {
+	query := r.URL.Query()
+	since, err := parseDate(query.Get("since"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuditLogRequest{Actor: query.Get("actor"), Action: query.Get("action"), Since: since}, nil
+}
@@ -0,0 +1,54 @@
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRequestBody = errors.New("invalid request body provided")
+	ErrRegexRule   = errors.New("invalid regular expression provided")
+	ErrRuleID      = errors.New("missing or invalid rule id provided")
+)
+
+type BlocklistRuleRequest struct {
+	Kind    string `json:"kind" validate:"required,oneof=name owner regex topic"`
+	Pattern string `json:"pattern" validate:"required,max=500"`
+}
+
+// NewBlocklistRuleRequest creates blocklist rule input from the json request body
+func NewBlocklistRuleRequest(r *http.Request) (*BlocklistRuleRequest, error) {
+	var ruleRequest BlocklistRuleRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&ruleRequest); err != nil {
+		return nil, ErrRequestBody
+	}
+
+	if ruleRequest.Kind == "regex" {
+		if _, err := regexp.Compile(ruleRequest.Pattern); err != nil {
+			return nil, ErrRegexRule
+		}
+	}
+
+	return &ruleRequest, nil
+}
+
+type RemoveBlocklistRuleRequest struct {
+	RuleID uuid.UUID
+}
+
+// NewRemoveBlocklistRuleRequest creates remove rule input from the rule id url parameter
+func NewRemoveBlocklistRuleRequest(r *http.Request) (*RemoveBlocklistRuleRequest, error) {
+	ruleID, err := uuid.Parse(chi.URLParam(r, "ruleId"))
+
+	if err != nil {
+		return nil, ErrRuleID
+	}
+
+	return &RemoveBlocklistRuleRequest{RuleID: ruleID}, nil
+}
@@ -0,0 +1,17 @@
+package input
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type OwnerRequest struct {
+	Login string `validate:"required,max=100"`
+}
+
+// NewOwnerRequest creates owner input from the url parameters
+func NewOwnerRequest(r *http.Request) *OwnerRequest {
+	login := chi.URLParam(r, "login")
+
+	return &OwnerRequest{Login: login}
+}
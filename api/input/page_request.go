@@ -0,0 +1,46 @@
+package input
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+const defaultPageLimit = 100
+
+var ErrCursor = errors.New("invalid cursor provided")
+
+type PageRequest struct {
+	Limit  int `validate:"min=1,max=1000"`
+	Cursor *types.Cursor
+}
+
+// NewPageRequest creates page input from the limit and cursor query parameters
+func NewPageRequest(r *http.Request) (*PageRequest, error) {
+	query := r.URL.Query()
+	pageRequest := &PageRequest{Limit: defaultPageLimit}
+
+	if rawLimit := query.Get("limit"); rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+
+		if err != nil {
+			return nil, ErrLimit
+		}
+
+		pageRequest.Limit = limit
+	}
+
+	if rawCursor := query.Get("cursor"); rawCursor != "" {
+		cursor, err := types.DecodeCursor(rawCursor)
+
+		if err != nil || !cursor.HasValidValue() {
+			return nil, ErrCursor
+		}
+
+		pageRequest.Cursor = cursor
+	}
+
+	return pageRequest, nil
+}
@@ -0,0 +1,32 @@
+package input
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+var ErrKeepHistory = errors.New("invalid keep_history flag provided")
+
+type RemoveLanguageRequest struct {
+	LanguageName string `validate:"required,supportedLanguage"`
+	KeepHistory  bool
+}
+
+// NewRemoveLanguageRequest creates remove language input, the history is not kept by default
+func NewRemoveLanguageRequest(r *http.Request) (*RemoveLanguageRequest, error) {
+	removeLanguageRequest := &RemoveLanguageRequest{LanguageName: chi.URLParam(r, "languageName")}
+
+	if rawKeepHistory := r.URL.Query().Get("keep_history"); rawKeepHistory != "" {
+		keepHistory, err := strconv.ParseBool(rawKeepHistory)
+
+		if err != nil {
+			return nil, ErrKeepHistory
+		}
+
+		removeLanguageRequest.KeepHistory = keepHistory
+	}
+
+	return removeLanguageRequest, nil
+}
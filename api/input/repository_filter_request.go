@@ -0,0 +1,98 @@
+package input
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrStarsFilter = errors.New("invalid stars filter provided")
+	ErrDateFilter  = errors.New("invalid date filter provided, use YYYY-MM-DD or RFC 3339")
+)
+
+type RepositoryFilterRequest struct {
+	MinStars      *int64 `validate:"omitempty,min=0"`
+	MaxStars      *int64 `validate:"omitempty,min=0"`
+	Owner         string `validate:"omitempty,max=100"`
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Sort          string `validate:"oneof=stars name created_at"`
+	Order         string `validate:"oneof=asc desc"`
+}
+
+// NewRepositoryFilterRequest creates filter and sort input from the query parameters,
+// the list is sorted by stars descending by default and by name ascending if only the sort field name is provided
+func NewRepositoryFilterRequest(r *http.Request) (*RepositoryFilterRequest, error) {
+	query := r.URL.Query()
+	filterRequest := &RepositoryFilterRequest{
+		Owner: query.Get("owner"),
+		Sort:  query.Get("sort"),
+		Order: query.Get("order"),
+	}
+
+	var err error
+
+	if filterRequest.MinStars, err = parseStars(query.Get("min_stars")); err != nil {
+		return nil, err
+	}
+
+	if filterRequest.MaxStars, err = parseStars(query.Get("max_stars")); err != nil {
+		return nil, err
+	}
+
+	if filterRequest.MinStars != nil && filterRequest.MaxStars != nil && *filterRequest.MinStars > *filterRequest.MaxStars {
+		return nil, ErrStarsFilter
+	}
+
+	if filterRequest.CreatedAfter, err = parseDate(query.Get("created_after")); err != nil {
+		return nil, err
+	}
+
+	if filterRequest.CreatedBefore, err = parseDate(query.Get("created_before")); err != nil {
+		return nil, err
+	}
+
+	if filterRequest.Sort == "" {
+		filterRequest.Sort = "stars"
+	}
+
+	if filterRequest.Order == "" {
+		filterRequest.Order = "desc"
+
+		if filterRequest.Sort == "name" {
+			filterRequest.Order = "asc"
+		}
+	}
+
+	return filterRequest, nil
+}
+
+func parseStars(value string) (*int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	stars, err := strconv.ParseInt(value, 10, 64)
+
+	if err != nil {
+		return nil, ErrStarsFilter
+	}
+
+	return &stars, nil
+}
+
+func parseDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return &date, nil
+		}
+	}
+
+	return nil, ErrDateFilter
+}
@@ -0,0 +1,33 @@
+package input
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type RepositoryRequest struct {
+	RepositoryID uuid.UUID
+}
+
+// NewRepositoryRequest creates repository input from the repository id url parameter
+func NewRepositoryRequest(r *http.Request) (*RepositoryRequest, error) {
+	repoID, err := uuid.Parse(chi.URLParam(r, "repositoryId"))
+
+	if err != nil {
+		return nil, ErrRepoID
+	}
+
+	return &RepositoryRequest{RepositoryID: repoID}, nil
+}
+
+type RepositoryNameRequest struct {
+	Owner string `validate:"required,max=100"`
+	Name  string `validate:"required,max=100"`
+}
+
+// NewRepositoryNameRequest creates repository input from the owner and name url parameters
+func NewRepositoryNameRequest(r *http.Request) *RepositoryNameRequest {
+	return &RepositoryNameRequest{Owner: chi.URLParam(r, "owner"), Name: chi.URLParam(r, "name")}
+}
@@ -0,0 +1,39 @@
+package input
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+const defaultSearchLimit = 20
+
+var ErrLimit = errors.New("invalid limit provided")
+
+type SearchRepositoriesRequest struct {
+	Query        string `validate:"required,max=200"`
+	LanguageName string `validate:"omitempty,supportedLanguage"`
+	Limit        int    `validate:"min=1,max=100"`
+}
+
+// NewSearchRepositoriesRequest creates search input from the query parameters
+func NewSearchRepositoriesRequest(r *http.Request) (*SearchRepositoriesRequest, error) {
+	query := r.URL.Query()
+	limit := defaultSearchLimit
+
+	if rawLimit := query.Get("limit"); rawLimit != "" {
+		parsedLimit, err := strconv.Atoi(rawLimit)
+
+		if err != nil {
+			return nil, ErrLimit
+		}
+
+		limit = parsedLimit
+	}
+
+	return &SearchRepositoriesRequest{
+		Query:        query.Get("q"),
+		LanguageName: query.Get("language"),
+		Limit:        limit,
+	}, nil
+}
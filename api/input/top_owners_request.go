@@ -0,0 +1,16 @@
+package input
+
+import (
+	"net/http"
+)
+
+type TopOwnersRequest struct {
+	LanguageName string `validate:"omitempty,supportedLanguage"`
+}
+
+// NewTopOwnersRequest creates top owners input, the language filter is optional
+func NewTopOwnersRequest(r *http.Request) *TopOwnersRequest {
+	language := r.URL.Query().Get("language")
+
+	return &TopOwnersRequest{LanguageName: language}
+}
@@ -0,0 +1,19 @@
+package input
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type TopicRepositoriesRequest struct {
+	Topic        string `validate:"required,max=50"`
+	LanguageName string `validate:"omitempty,supportedLanguage"`
+}
+
+// NewTopicRepositoriesRequest creates topic repositories input, the language filter is optional
+func NewTopicRepositoriesRequest(r *http.Request) *TopicRepositoriesRequest {
+	topic := chi.URLParam(r, "topic")
+	language := r.URL.Query().Get("language")
+
+	return &TopicRepositoriesRequest{Topic: topic, LanguageName: language}
+}
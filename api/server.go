@@ -1,89 +1,388 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"github.com/go-chi/chi/v5"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	// nolint: goimports
 	_ "github.com/lib/pq"
 
 	apiHandlers "github.com/pavbis/repositories-api/api/handlers"
+	"github.com/pavbis/repositories-api/application/client"
 	"github.com/pavbis/repositories-api/application/storage"
-)
-
-var (
-	userName = os.Getenv("AUTH_USER")
-	password = os.Getenv("AUTH_PASS")
+	"github.com/pavbis/repositories-api/application/types"
+	"github.com/pavbis/repositories-api/config"
+	"github.com/pavbis/repositories-api/db/migrations"
+	"github.com/pavbis/repositories-api/db/pool"
+	"github.com/pavbis/repositories-api/db/replica"
+	"github.com/pavbis/repositories-api/metrics"
+	"github.com/pavbis/repositories-api/tracing"
 )
 
 // Server represents server
 type Server struct {
-	router *chi.Mux
-	logger *log.Logger
-	db     *sql.DB
+	config        *config.Config
+	router        *chi.Mux
+	logger        *slog.Logger
+	db            *sql.DB
+	readDB        *sql.DB
+	reads         *replica.Router
+	httpClient    client.HTTPClient
+	metrics       *metrics.Metrics
+	authenticator apiHandlers.Authenticator
+	rateLimiter   storage.LimitsRate
+	rateLimits    apiHandlers.RateLimits
+
+	// jobs tracks the running imports, jobsCtx is cancelled when they do not finish in time on shutdown
+	jobs       sync.WaitGroup
+	jobsCtx    context.Context
+	cancelJobs context.CancelFunc
+
+	// shutdownTracing flushes the pending spans, it is nil when the server was not initialized
+	shutdownTracing func(context.Context) error
+}
+
+// defaultRateLimits protects the routes which build large responses or call the GitHub api,
+// the authentication limit is taken per remote address before the credentials are checked.
+// The configured limits override them per route
+var defaultRateLimits = apiHandlers.RateLimits{
+	"authentication":                     {Requests: 1200, Period: time.Minute},
+	"default":                            {Requests: 600, Period: time.Minute},
+	"GET /api/languages":                 {Requests: 30, Period: time.Minute},
+	"POST /api/languages/{languageName}": {Requests: 10, Period: time.Minute},
 }
 
 // Initialize initializes the server with necessary deps
-func (s *Server) Initialize() {
+func (s *Server) Initialize(cfg *config.Config) {
+	s.config = cfg
 	s.router = chi.NewRouter()
-	s.logger = log.New(os.Stdout, "", log.LstdFlags)
+	s.logger = cfg.Log.NewLogger(os.Stdout)
+	slog.SetDefault(s.logger)
 
 	var err error
-	s.db, err = sql.Open("postgres", os.Getenv("DATABASE_URL"))
+	s.shutdownTracing, err = tracing.Setup(context.Background(), cfg.Tracing)
 	if err != nil {
-		s.logger.Fatal(err)
+		s.fatal(err)
+	}
+
+	// the write pool serves the mutating requests and the authentication, the read pool the read only requests
+	s.db, err = pool.Open(cfg.Database.URL, cfg.Database.Write)
+	if err != nil {
+		s.fatal(err)
+	}
+
+	// with a replica the read pool connects to it and the router falls back to the write pool
+	if cfg.Database.ReplicaURL != "" {
+		s.readDB, err = pool.Open(cfg.Database.ReplicaURL, cfg.Database.Read)
+		if err != nil {
+			s.fatal(err)
+		}
+
+		s.reads = replica.NewRouter(s.db, s.readDB, cfg.Database.ReadYourWrites, s.logger)
+	} else {
+		s.readDB, err = pool.Open(cfg.Database.URL, cfg.Database.Read)
+		if err != nil {
+			s.fatal(err)
+		}
+
+		s.reads = replica.NewRouter(s.readDB, nil, false, s.logger)
 	}
 
 	err = s.db.Ping()
 	if err != nil {
-		s.logger.Fatal(err)
+		s.fatal(err)
+	}
+
+	if cfg.Database.AutoMigrate {
+		if err = migrations.NewMigrator(s.db, s.logger).Up(context.Background()); err != nil {
+			s.fatal(err)
+		}
 	}
 
+	s.metrics = metrics.New(storage.NewPostgresReadStore(s.reads), map[string]*sql.DB{"write": s.db, "read": s.readDB})
+	s.httpClient = client.NewRealHTTPClient(cfg.GitHub.BaseURL, cfg.GitHub.Timeout, tracing.Transport(s.metrics.InstrumentGitHubTransport(nil)))
+	s.jobsCtx, s.cancelJobs = context.WithCancel(context.Background())
+
+	s.initializeAuthentication()
+	s.initializeRateLimiting()
+	s.router.Use(
+		apiHandlers.RequestIDMiddleware,
+		tracing.Middleware,
+		apiHandlers.AccessLogMiddleware(s.logger),
+		s.metrics.HTTPMiddleware,
+	)
 	s.initializeRoutes()
 }
 
-// Run starts the server on the provided port
-func (s *Server) Run(addr string) {
+// fatal logs the error which prevents the server from starting and exits
+func (s *Server) fatal(err error) {
+	s.logger.Error("initialization failed", slog.String("error", err.Error()))
+	os.Exit(1)
+}
+
+func (s *Server) initializeAuthentication() {
+	auth := s.config.Auth
+
+	// the configured user and pass belong to the admin, the users list adds users with narrower roles
+	credentials, err := apiHandlers.ParseCredentials(auth.Users)
+	if err != nil {
+		s.fatal(err)
+	}
+
+	credentials = append(credentials, apiHandlers.Credentials{
+		User: auth.User,
+		Pass: auth.Pass,
+		Role: types.AdminRole,
+	})
+	authenticators := []apiHandlers.Authenticator{
+		apiHandlers.NewBasicAuthenticator(credentials...),
+		apiHandlers.NewAPIKeyAuthenticator(storage.NewPostgresAPIKeyStore(s.db)),
+	}
+
+	// further basic auth users with bcrypt hashed passwords can be managed in an htpasswd file
+	if auth.HtpasswdFile != "" {
+		htpasswdAuthenticator, err := apiHandlers.NewHtpasswdAuthenticator(auth.HtpasswdFile)
+		if err != nil {
+			s.fatal(err)
+		}
+
+		authenticators = append(authenticators, htpasswdAuthenticator)
+	}
+
+	// bearer tokens of the identity provider are accepted once its key set is configured
+	if auth.JWT.JWKS != "" {
+		authenticators = append(authenticators, s.jwtAuthenticator(auth.JWT))
+	}
+
+	s.authenticator = apiHandlers.NewChainAuthenticator(authenticators...)
+}
+
+func (s *Server) initializeRateLimiting() {
+	overrides, err := apiHandlers.ParseRateLimits(s.config.RateLimit.Limits)
+	if err != nil {
+		s.fatal(err)
+	}
+
+	s.rateLimits = make(apiHandlers.RateLimits, len(defaultRateLimits)+len(overrides))
+	for route, limit := range defaultRateLimits {
+		s.rateLimits[route] = limit
+	}
+	for route, limit := range overrides {
+		s.rateLimits[route] = limit
+	}
+
+	// the buckets are kept in postgres when several replicas have to share them
+	if s.config.RateLimit.Store == "postgres" {
+		s.rateLimiter = storage.NewPostgresRateLimitStore(s.db)
+	} else {
+		s.rateLimiter = storage.NewInMemoryRateLimitStore()
+	}
+}
+
+func (s *Server) jwtAuthenticator(cfg config.JWTConfig) apiHandlers.Authenticator {
+	keys, err := apiHandlers.NewJWKSKeySet(cfg.JWKS, cfg.RefreshInterval)
+	if err != nil {
+		s.fatal(err)
+	}
+
+	roleMapping, err := apiHandlers.ParseRoleMapping(cfg.RoleMapping)
+	if err != nil {
+		s.fatal(err)
+	}
+
+	return apiHandlers.NewJWTAuthenticator(keys, apiHandlers.JWTConfig{
+		Issuer:      cfg.Issuer,
+		Audience:    cfg.Audience,
+		RolesClaim:  cfg.RolesClaim,
+		RoleMapping: roleMapping,
+		Leeway:      30 * time.Second,
+	})
+}
+
+// Run serves the api on the configured address until SIGINT or SIGTERM is received and shuts it down gracefully
+func (s *Server) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", s.config.Server.Addr)
+	if err != nil {
+		return errors.Join(err, s.closeDB())
+	}
+
+	return s.serve(ctx, listener)
+}
+
+// serve serves the api until the context is done, then waits for the in-flight requests and import jobs
+func (s *Server) serve(ctx context.Context, listener net.Listener) error {
 	srv := &http.Server{
 		Handler:           s.router,
-		Addr:              addr,
-		WriteTimeout:      15 * time.Second,
-		ReadTimeout:       15 * time.Second,
-		IdleTimeout:       120 * time.Second,
-		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      s.config.Server.WriteTimeout,
+		ReadTimeout:       s.config.Server.ReadTimeout,
+		IdleTimeout:       s.config.Server.IdleTimeout,
+		ReadHeaderTimeout: s.config.Server.ReadHeaderTimeout,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.Serve(listener) }()
+
+	select {
+	case err := <-serveErr:
+		return errors.Join(err, s.shutdown(srv))
+	case <-ctx.Done():
+		s.logger.Info("shutting down, draining in-flight requests and import jobs")
+	}
+
+	return s.shutdown(srv)
+}
+
+// shutdown stops accepting requests and waits for the running ones until the shutdown timeout,
+// the imports still running then are interrupted before their next repository and the db pool is closed
+func (s *Server) shutdown(srv *http.Server) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Server.ShutdownTimeout)
+	defer cancel()
+
+	err := srv.Shutdown(ctx)
+
+	if err != nil {
+		s.logger.Warn("shutdown timeout exceeded, interrupting running imports", slog.String("error", err.Error()))
+	}
+
+	s.cancelJobs()
+	s.jobs.Wait()
+
+	if s.shutdownTracing != nil {
+		flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelFlush()
+
+		err = errors.Join(err, s.shutdownTracing(flushCtx))
 	}
 
-	s.logger.Fatal(srv.ListenAndServe())
+	return errors.Join(err, s.closeDB())
+}
+
+// closeDB closes the connection pools, the read pool is nil when the server was not initialized
+func (s *Server) closeDB() error {
+	err := s.db.Close()
+
+	if s.readDB != nil {
+		err = errors.Join(err, s.readDB.Close())
+	}
+
+	return err
+}
+
+// trackJob registers the handler as job which shutdown waits for. The job keeps running when the client
+// disconnects and is only cancelled when it does not finish within the shutdown timeout.
+func (s *Server) trackJob(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.jobs.Add(1)
+		defer s.jobs.Done()
+
+		ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+		defer cancel()
+
+		stop := context.AfterFunc(s.jobsCtx, cancel)
+		defer stop()
+
+		handler(w, r.WithContext(ctx))
+	}
 }
 
 func (s *Server) initializeRoutes() {
-	// Health check
+	// Health checks, the only routes without authentication so load balancers can reach them
 	s.router.Get("/api/health", apiHandlers.HealthRequestHandler)
+	s.router.Get("/api/health/live", apiHandlers.HealthRequestHandler)
+	rateLimits, _ := s.httpClient.(client.ReportsRateLimit)
+	readiness := apiHandlers.NewReadinessChecker(rateLimits, migrations.LatestVersion())
+	s.router.Get("/api/health/ready", s.handleRequestWithDBInstance(readiness.ReadinessRequestHandler))
+
+	// Metrics, the scrape counts the repositories so the scraper authenticates like any other reader
+	s.GetWithRole("/metrics", types.ReaderRole, s.metrics.Handler().ServeHTTP)
 
 	// Language
-	s.router.Post("/api/languages/{languageName}", s.handleRequestWithDBInstance(apiHandlers.ReceiveRepositoriesRequestHandler))
-	s.GetWithBasicAuth("/api/languages/{languageName}", s.handleRequestWithDBInstance(apiHandlers.ReadRepositoriesRequestHandler))
-	s.GetWithBasicAuth("/api/languages", s.handleRequestWithDBInstance(apiHandlers.ListLanguagesAndRepositoriesRequestHandler))
-	s.GetWithBasicAuth("/api/stats/count-repositories", s.handleRequestWithDBInstance(apiHandlers.CountRepositoriesStarsForLanguagesRequestHandler))
+	s.PostWithRole("/api/languages/{languageName}", types.ImporterRole, s.trackJob(s.handleRequestWithDBInstance(apiHandlers.NewReceiveRepositoriesRequestHandler(s.httpClient, s.metrics, s.reads))))
+	s.GetWithRole("/api/languages/{languageName}", types.ReaderRole, s.handleReadRequestWithDBInstance(apiHandlers.ReadRepositoriesRequestHandler))
+	s.DeleteWithRole("/api/languages/{languageName}", types.AdminRole, s.handleRequestWithDBInstance(apiHandlers.RemoveLanguageRequestHandler))
+	s.GetWithRole("/api/languages", types.ReaderRole, s.handleReadRequestWithDBInstance(apiHandlers.ListLanguagesAndRepositoriesRequestHandler))
+	s.GetWithRole("/api/stats/count-repositories", types.ReaderRole, s.handleReadRequestWithDBInstance(apiHandlers.CountRepositoriesStarsForLanguagesRequestHandler))
 
 	// Repositories
-	s.router.Post("/api/repositories/{repositoryId}", s.handleRequestWithDBInstance(apiHandlers.RemoveRepositoryRequestHandler))
-	s.GetWithBasicAuth("/api/stats/top-list", s.handleRequestWithDBInstance(apiHandlers.TopRepositoryForLanguageRequestHandler))
+	s.DeleteWithRole("/api/repositories/{repositoryId}", types.AdminRole, s.handleRequestWithDBInstance(apiHandlers.RemoveRepositoryRequestHandler))
+	s.PostWithRole("/api/repositories/{repositoryId}/restore", types.AdminRole, s.handleRequestWithDBInstance(apiHandlers.RestoreRepositoryRequestHandler))
+	// deprecated alias of the DELETE route
+	s.PostWithRole("/api/repositories/{repositoryId}", types.AdminRole, apiHandlers.DeprecatedRouteMiddleware(
+		"use DELETE /api/repositories/{repositoryId} instead", s.handleRequestWithDBInstance(apiHandlers.RemoveRepositoryRequestHandler)))
+	s.GetWithRole("/api/stats/top-list", types.ReaderRole, s.handleReadRequestWithDBInstance(apiHandlers.TopRepositoryForLanguageRequestHandler))
+	s.GetWithRole("/api/repositories/search", types.ReaderRole, s.handleReadRequestWithDBInstance(apiHandlers.SearchRepositoriesRequestHandler))
+	s.GetWithRole("/api/repositories/{repositoryId}", types.ReaderRole, s.handleReadRequestWithDBInstance(apiHandlers.ReadRepositoryRequestHandler))
+	s.GetWithRole("/api/repositories/{owner}/{name}", types.ReaderRole, s.handleReadRequestWithDBInstance(apiHandlers.ReadRepositoryByNameRequestHandler))
+
+	// Blocklist
+	s.GetWithRole("/api/blocklist", types.ReaderRole, s.handleReadRequestWithDBInstance(apiHandlers.ListBlocklistRulesRequestHandler))
+	s.PostWithRole("/api/blocklist", types.AdminRole, s.handleRequestWithDBInstance(apiHandlers.AddBlocklistRuleRequestHandler))
+	s.DeleteWithRole("/api/blocklist/{ruleId}", types.AdminRole, s.handleRequestWithDBInstance(apiHandlers.RemoveBlocklistRuleRequestHandler))
+
+	// Administration
+	s.GetWithRole("/api/admin/health", types.AdminRole, s.handleRequestWithDBInstance(readiness.ReadinessDetailsRequestHandler))
+	s.GetWithRole("/api/admin/audit", types.AdminRole, s.handleReadRequestWithDBInstance(apiHandlers.ReadAuditLogRequestHandler))
+	s.GetWithRole("/api/admin/api-keys", types.AdminRole, s.handleReadRequestWithDBInstance(apiHandlers.ListAPIKeysRequestHandler))
+	s.PostWithRole("/api/admin/api-keys", types.AdminRole, s.handleRequestWithDBInstance(apiHandlers.CreateAPIKeyRequestHandler))
+	s.DeleteWithRole("/api/admin/api-keys/{keyId}", types.AdminRole, s.handleRequestWithDBInstance(apiHandlers.RevokeAPIKeyRequestHandler))
+
+	// Owners
+	s.GetWithRole("/api/owners/{login}", types.ReaderRole, s.handleReadRequestWithDBInstance(apiHandlers.ReadOwnerRequestHandler))
+	s.GetWithRole("/api/stats/top-owners", types.ReaderRole, s.handleReadRequestWithDBInstance(apiHandlers.TopOwnersRequestHandler))
+
+	// Topics
+	s.GetWithRole("/api/topics", types.ReaderRole, s.handleReadRequestWithDBInstance(apiHandlers.ListTopicsRequestHandler))
+	s.GetWithRole("/api/topics/{topic}/repositories", types.ReaderRole, s.handleReadRequestWithDBInstance(apiHandlers.ReadTopicRepositoriesRequestHandler))
+}
+
+// GetWithRole registers GET route which requires authenticated client with provided role
+func (s *Server) GetWithRole(path string, role types.Role, handler http.HandlerFunc) {
+	s.router.Get(path, s.withRole(role, handler))
 }
 
-func (s *Server) GetWithBasicAuth(path string, handler http.HandlerFunc) {
-	s.router.Get(path, apiHandlers.BasicAuthMiddleware(userName, password, handler))
+// PostWithRole registers POST route which requires authenticated client with provided role
+func (s *Server) PostWithRole(path string, role types.Role, handler http.HandlerFunc) {
+	s.router.Post(path, s.withRole(role, handler))
+}
+
+// DeleteWithRole registers DELETE route which requires authenticated client with provided role
+func (s *Server) DeleteWithRole(path string, role types.Role, handler http.HandlerFunc) {
+	s.router.Delete(path, s.withRole(role, handler))
+}
+
+func (s *Server) withRole(role types.Role, handler http.HandlerFunc) http.HandlerFunc {
+	return apiHandlers.AuthenticationRateLimitMiddleware(s.rateLimiter, s.rateLimits,
+		apiHandlers.AuthenticationMiddleware(s.authenticator,
+			apiHandlers.RateLimitMiddleware(s.rateLimiter, s.rateLimits,
+				apiHandlers.RequireRoleMiddleware(role, handler))))
 }
 
 // RequestHandlerFunction is the function which represents any handler
 type RequestHandlerFunction func(db storage.Executor, w http.ResponseWriter, r *http.Request)
 
+// handleRequestWithDBInstance passes the write pool to the handler
 func (s *Server) handleRequestWithDBInstance(handler RequestHandlerFunction) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		handler(s.db, w, r)
+		handler(tracing.NewExecutor(r.Context(), s.db), w, r)
+	}
+}
+
+// handleReadRequestWithDBInstance passes the read pool or the replica to the handler, the handler must not write
+func (s *Server) handleReadRequestWithDBInstance(handler RequestHandlerFunction) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler(tracing.NewExecutor(r.Context(), s.reads), w, r)
 	}
 }
@@ -2,24 +2,34 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/pavbis/repositories-api/application/storage"
 	"github.com/pavbis/repositories-api/application/types"
 	"github.com/pavbis/repositories-api/application/writemodel"
+	"github.com/pavbis/repositories-api/config"
 )
 
 var s Server
 
+const (
+	readerUser = "integration-reader"
+	readerPass = "integration-reader-pass"
+)
+
 func TestMain(m *testing.M) {
+	_ = os.Setenv("AUTH_USERS", readerUser+":"+readerPass+":reader")
 	initializeServer()
 
 	code := m.Run()
@@ -34,8 +44,55 @@ func TestHealthStatus(t *testing.T) {
 	checkMessageValue(t, response.Body.Bytes(), "status", "OK")
 }
 
+func TestLiveness(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/api/health/live", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusOK, response.Code)
+	checkMessageValue(t, response.Body.Bytes(), "status", "OK")
+}
+
+func TestReadiness(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/api/health/ready", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	if body := response.Body.String(); body != `{"status":"OK"}` {
+		t.Errorf("Expected the status only. Got %s", body)
+	}
+}
+
+func TestReadinessDetails(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/admin/health", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusOK, response.Code)
+	checkMessageValue(t, response.Body.Bytes(), "status", "OK")
+
+	var body struct {
+		Components map[string]struct {
+			Status string `json:"status"`
+		} `json:"components"`
+	}
+	_ = json.Unmarshal(response.Body.Bytes(), &body)
+
+	for _, component := range []string{"database", "migrations"} {
+		if body.Components[component].Status != "up" {
+			t.Errorf("Expected %s to be up. Got %s", component, body.Components[component].Status)
+		}
+	}
+}
+
+func TestMetricsWithoutCredentials(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusUnauthorized, response.Code)
+}
+
 func TestPostLanguageWithInvalidLanguageName(t *testing.T) {
-	req, _ := http.NewRequest(http.MethodPost, "/api/languages/rust", nil)
+	req := authRequest(http.MethodPost, "/api/languages/rust", nil)
 	response := executeRequest(req)
 
 	checkResponseCode(t, http.StatusBadRequest, response.Code)
@@ -46,6 +103,120 @@ func TestPostLanguageWithInvalidLanguageName(t *testing.T) {
 		"Key: 'LanguageRepositoriesRequest.LanguageName' Error:Field validation for 'LanguageName' failed on the 'supportedLanguage' tag")
 }
 
+func TestPostLanguageWithoutCredentials(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "/api/languages/go", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusUnauthorized, response.Code)
+}
+
+func TestPostLanguageWithReaderRole(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "/api/languages/go", nil)
+	req.SetBasicAuth(readerUser, readerPass)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusForbidden, response.Code)
+	checkMessageValue(t, response.Body.Bytes(), "error", "role importer required")
+}
+
+func TestGetRepositoriesWithReaderRole(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/api/languages/java", nil)
+	req.SetBasicAuth(readerUser, readerPass)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusOK, response.Code)
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	response := executeRequest(authRequest(http.MethodGet, "/api/languages", nil))
+
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	if limit := response.Header().Get("X-RateLimit-Limit"); limit != "30" {
+		t.Errorf("Expected rate limit is 30. Got %s", limit)
+	}
+
+	if response.Header().Get("X-RateLimit-Remaining") == "" {
+		t.Error("Expected remaining requests header")
+	}
+}
+
+func TestAuditLogRecordsFailedDeletion(t *testing.T) {
+	repositoryID := "b3e2d6a8-2c3f-4b5e-9d1a-7f6c5e4d3c2b"
+	response := executeRequest(authRequest(http.MethodDelete, "/api/repositories/"+repositoryID, nil))
+
+	checkResponseCode(t, http.StatusNotFound, response.Code)
+
+	response = executeRequest(authRequest(http.MethodGet, "/api/admin/audit?action=repository.delete&limit=1", nil))
+
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var entries []types.AuditEntry
+	page := readPage(response.Body.Bytes())
+	_ = json.Unmarshal(page.Data, &entries)
+
+	if len(entries) != 1 {
+		t.Fatalf("Expected one audit entry. Got %d", len(entries))
+	}
+
+	entry := entries[0]
+
+	if entry.Actor != os.Getenv("AUTH_USER") || entry.Outcome != types.AuditFailure ||
+		len(entry.Targets) != 1 || entry.Targets[0] != repositoryID || entry.RequestID == "" {
+		t.Errorf("Got unexpected audit entry %+v", entry)
+	}
+}
+
+func TestReadAuditLogWithInvalidAction(t *testing.T) {
+	response := executeRequest(authRequest(http.MethodGet, "/api/admin/audit?action=repository.rename", nil))
+
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+}
+
+func TestReadAuditLogAsReader(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/api/admin/audit", nil)
+	req.SetBasicAuth(readerUser, readerPass)
+
+	checkResponseCode(t, http.StatusForbidden, executeRequest(req).Code)
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	body := bytes.NewBufferString(`{"name": "integration", "scopes": ["reader"]}`)
+	response := executeRequest(authRequest(http.MethodPost, "/api/admin/api-keys", body))
+
+	var created types.CreatedAPIKey
+	_ = json.Unmarshal(response.Body.Bytes(), &created)
+
+	checkResponseCode(t, http.StatusCreated, response.Code)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/languages/java", nil)
+	req.Header.Set("X-API-Key", created.Key)
+	checkResponseCode(t, http.StatusOK, executeRequest(req).Code)
+
+	req, _ = http.NewRequest(http.MethodPost, "/api/languages/java", nil)
+	req.Header.Set("Authorization", "Bearer "+created.Key)
+	checkResponseCode(t, http.StatusForbidden, executeRequest(req).Code)
+
+	response = executeRequest(authRequest(http.MethodDelete, fmt.Sprintf("/api/admin/api-keys/%s", created.UUID.String()), nil))
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/languages/java", nil)
+	req.Header.Set("X-API-Key", created.Key)
+	checkResponseCode(t, http.StatusUnauthorized, executeRequest(req).Code)
+}
+
+func TestCreateAPIKeyWithInvalidScope(t *testing.T) {
+	body := bytes.NewBufferString(`{"name": "integration", "scopes": ["superuser"]}`)
+	response := executeRequest(authRequest(http.MethodPost, "/api/admin/api-keys", body))
+
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkMessageValue(
+		t,
+		response.Body.Bytes(),
+		"error",
+		"Key: 'CreateAPIKeyRequest.Scopes[0]' Error:Field validation for 'Scopes[0]' failed on the 'oneof' tag")
+}
+
 func TestGetRepositoriesWithInvalidLanguageName(t *testing.T) {
 	req := authRequest(http.MethodGet, "/api/languages/rust", nil)
 	response := executeRequest(req)
@@ -63,7 +234,7 @@ func TestGetRepositoriesWithValidLanguageNameAndEmptyResultSet(t *testing.T) {
 	response := executeRequest(req)
 
 	checkResponseCode(t, http.StatusOK, response.Code)
-	checkResponseBodyIsEmptyArray(t, response.Body)
+	checkPageResponseBodyIsEmpty(t, response.Body.Bytes())
 }
 
 func TestStatisticsListWithEmptyRDBMS(t *testing.T) {
@@ -87,21 +258,160 @@ func TestGetRepositoriesWithValidLanguageName(t *testing.T) {
 	store := storage.NewPostgresWriteStore(s.db)
 	// we are using another client here to prevent real HTTP call to external api
 	client := &FakeJSONFileReadingClient{}
-	commandHandler := writemodel.NewWriteLanguageRepositoriesCommandHandler(client, store)
+	commandHandler := writemodel.NewWriteLanguageRepositoriesCommandHandler(client, store, storage.NewPostgresBlocklistStore(s.db))
 	pl := &types.ProgrammingLanguage{Name: "go"}
 	// write data to database
-	_, _ = commandHandler.HandleRepositories(pl)
+	_, _ = commandHandler.HandleRepositories(context.Background(), pl)
 
 	req := authRequest(http.MethodGet, "/api/languages/go", nil)
 	response := executeRequest(req)
 	expected, _ := readFileContent("testdata/internal_response_data.json")
 
 	checkResponseCode(t, http.StatusOK, response.Code)
-	checkResponseBody(t, response.Body.Bytes(), expected)
+	checkPageResponseBody(t, response.Body.Bytes(), expected)
+}
+
+func TestGetRepositoriesFollowingNextCursor(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/languages/go?limit=10", nil)
+	response := executeRequest(req)
+	firstPage := readPage(response.Body.Bytes())
+
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	if firstPage.NextCursor == nil {
+		t.Fatal("Expected next cursor for the first page. Got none")
+	}
+
+	req = authRequest(http.MethodGet, "/api/languages/go?limit=10&cursor="+*firstPage.NextCursor, nil)
+	response = executeRequest(req)
+	secondPage := readPage(response.Body.Bytes())
+
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	expected, _ := readFileContent("testdata/internal_response_data.json")
+	var expectedItems []interface{}
+	_ = json.Unmarshal(expected, &expectedItems)
+
+	var items []interface{}
+	_ = json.Unmarshal(secondPage.Data, &items)
+
+	if !reflect.DeepEqual(items, expectedItems[10:20]) {
+		t.Errorf("\n %v. \n %v", expectedItems[10:20], items)
+	}
+}
+
+func TestGetRepositoriesWithInvalidCursor(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/languages/go?cursor=invalid", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkMessageValue(t, response.Body.Bytes(), "error", "invalid cursor provided")
+}
+
+func TestGetRepositoriesWithStarsFilterAndNameSorting(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/languages/go?min_stars=60000&sort=name", nil)
+	response := executeRequest(req)
+	page := readPage(response.Body.Bytes())
+
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	expected := []byte(`[
+  {"full_name": "golang/go", "stars": 76744, "description": "The Go programming language"},
+  {"full_name": "kubernetes/kubernetes", "stars": 70187, "description": "Production-Grade Container Scheduling and Management"}
+]`)
+	checkResponseBody(t, page.Data, expected)
+}
+
+func TestGetRepositoriesWithInvalidStarsRange(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/languages/go?min_stars=100&max_stars=10", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkMessageValue(t, response.Body.Bytes(), "error", "invalid stars filter provided")
+}
+
+func TestGetRepositoriesWithInvalidSortField(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/languages/go?sort=forks", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkMessageValue(
+		t,
+		response.Body.Bytes(),
+		"error",
+		"Key: 'RepositoryFilterRequest.Sort' Error:Field validation for 'Sort' failed on the 'oneof' tag")
+}
+
+func TestGetRepositoriesWithCursorOfDifferentSorting(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/languages/go?limit=5", nil)
+	response := executeRequest(req)
+	firstPage := readPage(response.Body.Bytes())
+
+	if firstPage.NextCursor == nil {
+		t.Fatal("Expected next cursor for the first page. Got none")
+	}
+
+	req = authRequest(http.MethodGet, "/api/languages/go?sort=created_at&cursor="+*firstPage.NextCursor, nil)
+	response = executeRequest(req)
+
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkMessageValue(t, response.Body.Bytes(), "error", "invalid cursor provided")
+}
+
+func TestListLanguagesWithPageLimitOutOfRange(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/languages?limit=1001", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkMessageValue(
+		t,
+		response.Body.Bytes(),
+		"error",
+		"Key: 'PageRequest.Limit' Error:Field validation for 'Limit' failed on the 'max' tag")
+}
+
+func TestGetRepositoryByName(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/repositories/golang/go", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusOK, response.Code)
+	checkMessageValue(t, response.Body.Bytes(), "full_name", "golang/go")
+	checkMessageValue(t, response.Body.Bytes(), "owner", "golang")
+}
+
+func TestGetRepositoryWithValidExistingRepositoryId(t *testing.T) {
+	var repositoryUUIDAsString string
+	_ = s.db.QueryRow(`SELECT "repositoryId" FROM repositories WHERE full_name = 'golang/go'`).Scan(&repositoryUUIDAsString)
+
+	req := authRequest(http.MethodGet, fmt.Sprintf("/api/repositories/%s", repositoryUUIDAsString), nil)
+	response := executeRequest(req)
+
+	var repository struct {
+		FullName  string `json:"full_name"`
+		Languages []struct {
+			LanguageName string `json:"language_name"`
+			Rank         int    `json:"rank"`
+		} `json:"languages"`
+	}
+	_ = json.Unmarshal(response.Body.Bytes(), &repository)
+
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	if repository.FullName != "golang/go" || len(repository.Languages) != 1 || repository.Languages[0].Rank != 1 {
+		t.Errorf("Expected golang/go ranked first for go. Got %s", response.Body.String())
+	}
+}
+
+func TestGetRepositoryWithUnknownRepositoryId(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/repositories/34ffdec9-26e4-4c2f-b9ae-4dc9cb647dc5", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusNotFound, response.Code)
+	checkMessageValue(t, response.Body.Bytes(), "error", "repository not found")
 }
 
 func TestDeleteRepositoryWithInvalidRepositoryId(t *testing.T) {
-	req, _ := http.NewRequest(http.MethodPost, "/api/repositories/invalidUuid", nil)
+	req := authRequest(http.MethodPost, "/api/repositories/invalidUuid", nil)
 	response := executeRequest(req)
 
 	checkResponseCode(t, http.StatusBadRequest, response.Code)
@@ -109,7 +419,7 @@ func TestDeleteRepositoryWithInvalidRepositoryId(t *testing.T) {
 }
 
 func TestDeleteRepositoryWithValidRepositoryIdButInconsistentRepo(t *testing.T) {
-	req, _ := http.NewRequest(http.MethodPost, "/api/repositories/34ffdec9-26e4-4c2f-b9ae-4dc9cb647dc5", nil)
+	req := authRequest(http.MethodPost, "/api/repositories/34ffdec9-26e4-4c2f-b9ae-4dc9cb647dc5", nil)
 	response := executeRequest(req)
 
 	checkResponseCode(t, http.StatusNotFound, response.Code)
@@ -120,7 +430,7 @@ func TestDeleteRepositoryWithValidExistingRepositoryId(t *testing.T) {
 	var repositoryUUIDAsString string
 	_ = s.db.QueryRow(`SELECT "repositoryId" FROM repositories LIMIT 1`).Scan(&repositoryUUIDAsString)
 
-	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/repositories/%s", repositoryUUIDAsString), nil)
+	req := authRequest(http.MethodPost, fmt.Sprintf("/api/repositories/%s", repositoryUUIDAsString), nil)
 	response := executeRequest(req)
 	expected := bytes.NewBufferString(fmt.Sprintf("successfully deleted repository %s", repositoryUUIDAsString))
 
@@ -128,6 +438,79 @@ func TestDeleteRepositoryWithValidExistingRepositoryId(t *testing.T) {
 	checkResponseBody(t, response.Body.Bytes(), expected.Bytes())
 }
 
+func TestDeleteRepositoryUsingDeleteVerbAndRestoreIt(t *testing.T) {
+	var repositoryUUIDAsString string
+	_ = s.db.QueryRow(`SELECT "repositoryId" FROM repositories WHERE deleted_at IS NULL LIMIT 1`).Scan(&repositoryUUIDAsString)
+	repositoryURL := fmt.Sprintf("/api/repositories/%s", repositoryUUIDAsString)
+
+	response := executeRequest(authRequest(http.MethodDelete, repositoryURL, nil))
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	response = executeRequest(authRequest(http.MethodGet, repositoryURL, nil))
+	checkResponseCode(t, http.StatusNotFound, response.Code)
+
+	response = executeRequest(authRequest(http.MethodPost, repositoryURL+"/restore", nil))
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	response = executeRequest(authRequest(http.MethodPost, repositoryURL+"/restore", nil))
+	checkResponseCode(t, http.StatusNotFound, response.Code)
+	checkMessageValue(t, response.Body.Bytes(), "error", "deleted repository not found")
+
+	response = executeRequest(authRequest(http.MethodGet, repositoryURL, nil))
+	checkResponseCode(t, http.StatusOK, response.Code)
+}
+
+func TestDeletedRepositoryIsKeptDeletedOnReimport(t *testing.T) {
+	var repositoryUUIDAsString string
+	_ = s.db.QueryRow(`SELECT "repositoryId" FROM repositories WHERE full_name = 'avelino/awesome-go'`).Scan(&repositoryUUIDAsString)
+
+	response := executeRequest(authRequest(http.MethodDelete, fmt.Sprintf("/api/repositories/%s", repositoryUUIDAsString), nil))
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	store := storage.NewPostgresWriteStore(s.db)
+	commandHandler := writemodel.NewWriteLanguageRepositoriesCommandHandler(
+		&FakeJSONFileReadingClient{}, store, storage.NewPostgresBlocklistStore(s.db))
+	_, _ = commandHandler.HandleRepositories(context.Background(), &types.ProgrammingLanguage{Name: "go"})
+
+	response = executeRequest(authRequest(http.MethodGet, "/api/repositories/avelino/awesome-go", nil))
+	checkResponseCode(t, http.StatusNotFound, response.Code)
+
+	response = executeRequest(authRequest(http.MethodPost, fmt.Sprintf("/api/repositories/%s/restore", repositoryUUIDAsString), nil))
+	checkResponseCode(t, http.StatusOK, response.Code)
+}
+
+func TestPrunedRepositoryIsNotImportedAgain(t *testing.T) {
+	var repositoryUUIDAsString string
+	_ = s.db.QueryRow(`SELECT "repositoryId" FROM repositories WHERE full_name = 'avelino/awesome-go'`).Scan(&repositoryUUIDAsString)
+
+	response := executeRequest(authRequest(http.MethodDelete, fmt.Sprintf("/api/repositories/%s", repositoryUUIDAsString), nil))
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	_, _ = s.db.Exec(`UPDATE repositories SET deleted_at = NOW() - INTERVAL '1 year' WHERE "repositoryId" = $1`, repositoryUUIDAsString)
+
+	ctx := context.Background()
+	maintenance := storage.NewPostgresMaintenanceStore(s.db)
+	_, _ = maintenance.PruneAPIKeys(ctx, time.Now())
+	_, _ = maintenance.PruneRateLimitBuckets(ctx, time.Now())
+
+	commandHandler := writemodel.NewWriteLanguageRepositoriesCommandHandler(
+		&FakeJSONFileReadingClient{}, storage.NewPostgresWriteStore(s.db), storage.NewPostgresBlocklistStore(s.db))
+	_, _ = commandHandler.HandleRepositories(ctx, &types.ProgrammingLanguage{Name: "go"})
+
+	response = executeRequest(authRequest(http.MethodGet, "/api/repositories/avelino/awesome-go", nil))
+	checkResponseCode(t, http.StatusNotFound, response.Code)
+
+	response = executeRequest(authRequest(http.MethodPost, fmt.Sprintf("/api/repositories/%s/restore", repositoryUUIDAsString), nil))
+	checkResponseCode(t, http.StatusOK, response.Code)
+}
+
+func TestDeleteRepositoryWithoutCredentials(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodDelete, "/api/repositories/34ffdec9-26e4-4c2f-b9ae-4dc9cb647dc5", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusUnauthorized, response.Code)
+}
+
 // the repos for golang are persisted ATM
 func TestStatisticsTopListWithRecordsInRDBMS(t *testing.T) {
 	req := authRequest(http.MethodGet, "/api/stats/top-list", nil)
@@ -148,6 +531,170 @@ func TestStatisticsCountReposWithRecordsInRDBMS(t *testing.T) {
 	checkResponseBody(t, response.Body.Bytes(), expected)
 }
 
+// the repos for golang are persisted ATM
+func TestGetOwnerWithValidLogin(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/owners/golang", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusOK, response.Code)
+	checkMessageValue(t, response.Body.Bytes(), "login", "golang")
+	checkMessageValue(t, response.Body.Bytes(), "type", "organization")
+}
+
+func TestGetOwnerWithUnknownLogin(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/owners/unknown-owner-login", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusNotFound, response.Code)
+	checkMessageValue(t, response.Body.Bytes(), "error", "owner not found")
+}
+
+func TestTopOwnersWithInvalidLanguageName(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/stats/top-owners?language=rust", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkMessageValue(
+		t,
+		response.Body.Bytes(),
+		"error",
+		"Key: 'TopOwnersRequest.LanguageName' Error:Field validation for 'LanguageName' failed on the 'supportedLanguage' tag")
+}
+
+func TestTopicRepositoriesWithInvalidLanguageName(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/topics/kubernetes/repositories?language=rust", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkMessageValue(
+		t,
+		response.Body.Bytes(),
+		"error",
+		"Key: 'TopicRepositoriesRequest.LanguageName' Error:Field validation for 'LanguageName' failed on the 'supportedLanguage' tag")
+}
+
+func TestTopicRepositoriesWithUnknownTopic(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/topics/unknown-topic/repositories?language=go", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusOK, response.Code)
+	checkResponseBodyIsEmptyArray(t, response.Body)
+}
+
+// the repos for golang are persisted ATM
+func TestSearchRepositoriesWithMatchingQuery(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/repositories/search?q=container&language=go", nil)
+	response := executeRequest(req)
+
+	var results []map[string]interface{}
+	_ = json.Unmarshal(response.Body.Bytes(), &results)
+
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	if len(results) == 0 {
+		t.Errorf("Expected search results for %q. Got none", "container")
+	}
+}
+
+func TestSearchRepositoriesWithoutQuery(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/repositories/search?language=go", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkMessageValue(
+		t,
+		response.Body.Bytes(),
+		"error",
+		"Key: 'SearchRepositoriesRequest.Query' Error:Field validation for 'Query' failed on the 'required' tag")
+}
+
+func TestSearchRepositoriesWithInvalidLimit(t *testing.T) {
+	req := authRequest(http.MethodGet, "/api/repositories/search?q=web&limit=many", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkMessageValue(t, response.Body.Bytes(), "error", "invalid limit provided")
+}
+
+func TestBlocklistRuleIsAppliedOnImport(t *testing.T) {
+	body := bytes.NewBufferString(`{"kind": "name", "pattern": "avelino/awesome-go"}`)
+	response := executeRequest(authRequest(http.MethodPost, "/api/blocklist", body))
+
+	var rule types.BlocklistRule
+	_ = json.Unmarshal(response.Body.Bytes(), &rule)
+
+	checkResponseCode(t, http.StatusCreated, response.Code)
+
+	// the repository imported before the rule existed is removed right away
+	response = executeRequest(authRequest(http.MethodGet, "/api/repositories/avelino/awesome-go", nil))
+	checkResponseCode(t, http.StatusNotFound, response.Code)
+
+	store := storage.NewPostgresWriteStore(s.db)
+	commandHandler := writemodel.NewWriteLanguageRepositoriesCommandHandler(
+		&FakeJSONFileReadingClient{}, store, storage.NewPostgresBlocklistStore(s.db))
+	result, _ := commandHandler.HandleRepositories(context.Background(), &types.ProgrammingLanguage{Name: "go"})
+
+	if result.FilteredRepositories != 1 {
+		t.Errorf("Expected %d filtered repositories. Got %d", 1, result.FilteredRepositories)
+	}
+
+	response = executeRequest(authRequest(http.MethodGet, "/api/repositories/avelino/awesome-go", nil))
+	checkResponseCode(t, http.StatusNotFound, response.Code)
+
+	response = executeRequest(authRequest(http.MethodDelete, fmt.Sprintf("/api/blocklist/%s", rule.UUID.String()), nil))
+	checkResponseCode(t, http.StatusOK, response.Code)
+}
+
+func TestAddBlocklistRuleWithInvalidRegex(t *testing.T) {
+	body := bytes.NewBufferString(`{"kind": "regex", "pattern": "awesome-("}`)
+	response := executeRequest(authRequest(http.MethodPost, "/api/blocklist", body))
+
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkMessageValue(t, response.Body.Bytes(), "error", "invalid regular expression provided")
+}
+
+func TestAddBlocklistRuleWithInvalidKind(t *testing.T) {
+	body := bytes.NewBufferString(`{"kind": "license", "pattern": "MIT"}`)
+	response := executeRequest(authRequest(http.MethodPost, "/api/blocklist", body))
+
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkMessageValue(
+		t,
+		response.Body.Bytes(),
+		"error",
+		"Key: 'BlocklistRuleRequest.Kind' Error:Field validation for 'Kind' failed on the 'oneof' tag")
+}
+
+func TestDeleteLanguageKeepingHistory(t *testing.T) {
+	var repositoriesCount int64
+	_ = s.db.QueryRow(
+		`SELECT COUNT(*) FROM repositories JOIN programming_languages USING ("languageId") WHERE language_name = 'go'`).
+		Scan(&repositoriesCount)
+
+	response := executeRequest(authRequest(http.MethodDelete, "/api/languages/go?keep_history=true", nil))
+
+	var result types.LanguageRemovalResult
+	_ = json.Unmarshal(response.Body.Bytes(), &result)
+
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	if result.RemovedRepositories != repositoriesCount || result.ArchivedRepositories != repositoriesCount {
+		t.Errorf("Expected %d removed and archived repositories. Got %v", repositoriesCount, result)
+	}
+
+	response = executeRequest(authRequest(http.MethodDelete, "/api/languages/go", nil))
+
+	checkResponseCode(t, http.StatusNotFound, response.Code)
+	checkMessageValue(t, response.Body.Bytes(), "error", "language not found")
+}
+
+func TestDeleteLanguageWithoutCredentials(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodDelete, "/api/languages/go", nil)
+	response := executeRequest(req)
+
+	checkResponseCode(t, http.StatusUnauthorized, response.Code)
+}
+
 func TestStatisticsCountReposWithEmptyRDBMS(t *testing.T) {
 	if err := truncateProgrammingLanguagesTable(); err != nil {
 		t.Error(err)
@@ -161,11 +708,16 @@ func TestStatisticsCountReposWithEmptyRDBMS(t *testing.T) {
 }
 
 // helper functions start here
-// initializes the server, there is no need to execute s.Run(":1111")
+// initializes the server from the environment, there is no need to execute s.Run()
 // the http test recorder just collects the request/response information
 func initializeServer() {
+	cfg, _, err := config.Load(nil, os.LookupEnv)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	s = Server{}
-	s.Initialize()
+	s.Initialize(cfg)
 }
 
 // creates new instance of HTTP recorder
@@ -216,6 +768,30 @@ func checkResponseBodyIsEmptyArray(t *testing.T, respBody *bytes.Buffer) {
 	}
 }
 
+// reads the paginated response envelope
+func readPage(body []byte) types.Page {
+	var page types.Page
+	_ = json.Unmarshal(body, &page)
+
+	return page
+}
+
+// checks the data of the paginated response body
+func checkPageResponseBody(t *testing.T, body []byte, expected []byte) {
+	checkResponseBody(t, readPage(body).Data, expected)
+}
+
+// checks the paginated response body holds no data and no next cursor
+func checkPageResponseBodyIsEmpty(t *testing.T, body []byte) {
+	page := readPage(body)
+
+	checkResponseBodyIsEmptyArray(t, bytes.NewBuffer(page.Data))
+
+	if page.NextCursor != nil {
+		t.Errorf("expected no next cursor got %s", *page.NextCursor)
+	}
+}
+
 // removes all existing programming languages from "programming_languages" table.
 func truncateProgrammingLanguagesTable() error {
 	if _, err := s.db.Exec(`DELETE FROM programming_languages WHERE "languageId" IS NOT NULL`); err != nil {
@@ -255,7 +831,7 @@ func basicAuthValue() string {
 type FakeJSONFileReadingClient struct{}
 
 // FetchData fetches data from defined json file and fills the GitHubJSONResponse struct
-func (c *FakeJSONFileReadingClient) FetchData(language string) (*types.GitHubJSONResponse, error) {
+func (c *FakeJSONFileReadingClient) FetchData(ctx context.Context, language string) (*types.GitHubJSONResponse, error) {
 	fileContent, _ := readFileContent("testdata/external_response_data.json")
 
 	ghr := types.GitHubJSONResponse{}
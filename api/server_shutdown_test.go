@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pavbis/repositories-api/config"
+)
+
+// newShutdownTestServer creates server whose /job route runs the provided job
+func newShutdownTestServer(t *testing.T, job http.HandlerFunc) (*Server, net.Listener) {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Server.ShutdownTimeout = 100 * time.Millisecond
+
+	db, _ := sql.Open("postgres", "")
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	srv := &Server{config: cfg, router: chi.NewRouter(), logger: slog.New(slog.DiscardHandler), db: db,
+		jobsCtx: jobsCtx, cancelJobs: cancelJobs}
+	srv.router.Post("/job", srv.trackJob(job))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return srv, listener
+}
+
+// runJob serves until the job started, then triggers the shutdown and returns the result of serve
+func runJob(t *testing.T, srv *Server, listener net.Listener, started <-chan struct{}) error {
+	t.Helper()
+
+	ctx, shutdown := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.serve(ctx, listener) }()
+	go func() { _, _ = http.Post("http://"+listener.Addr().String()+"/job", "application/json", nil) }()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not start")
+	}
+
+	shutdown()
+
+	select {
+	case err := <-served:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+		return nil
+	}
+}
+
+func TestShutdownWaitsForRunningJob(t *testing.T) {
+	started, finished := make(chan struct{}), make(chan struct{})
+	srv, listener := newShutdownTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(20 * time.Millisecond)
+		close(finished)
+	})
+
+	if err := runJob(t, srv, listener, started); err != nil {
+		t.Errorf("Expected clean shutdown. Got %v", err)
+	}
+
+	select {
+	case <-finished:
+	default:
+		t.Error("Expected shutdown to wait for the running job")
+	}
+
+	if err := srv.db.Ping(); err == nil || err.Error() != "sql: database is closed" {
+		t.Errorf("Expected db pool to be closed. Got %v", err)
+	}
+}
+
+func TestShutdownInterruptsJobAfterTimeout(t *testing.T) {
+	started, interrupted := make(chan struct{}), make(chan struct{})
+	srv, listener := newShutdownTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+		close(interrupted)
+	})
+
+	if err := runJob(t, srv, listener, started); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected shutdown timeout error. Got %v", err)
+	}
+
+	select {
+	case <-interrupted:
+	default:
+		t.Error("Expected the job to be interrupted before shutdown returned")
+	}
+}
@@ -1,52 +1,77 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pavbis/repositories-api/application/types"
 )
 
 type HTTPClient interface {
-	FetchData(language string) (*types.GitHubJSONResponse, error)
+	FetchData(ctx context.Context, language string) (*types.GitHubJSONResponse, error)
+}
+
+// ReportsRateLimit is implemented by clients which remember the GitHub rate limit of their last response,
+// ok is false before the first response
+type ReportsRateLimit interface {
+	RateLimit() (limit types.GitHubRateLimit, ok bool)
 }
 
 // realHTTPClient is a wrapper to make real HTTP requests.
 type realHTTPClient struct {
-	client http.Client
+	client  http.Client
+	baseURL string
+
+	mu        sync.Mutex
+	rateLimit *types.GitHubRateLimit
 }
 
-// NewRealHTTPClient creates a RealHttpClient.
-func NewRealHTTPClient() HTTPClient {
+// NewRealHTTPClient creates a RealHttpClient for the GitHub api at the provided base url,
+// a nil transport sends the requests with the default transport.
+func NewRealHTTPClient(baseURL string, timeout time.Duration, transport http.RoundTripper) HTTPClient {
 	return &realHTTPClient{
 		client: http.Client{
-			Timeout: 5 * time.Second,
+			Timeout:   timeout,
+			Transport: transport,
 		},
+		baseURL: strings.TrimSuffix(baseURL, "/"),
 	}
 }
 
-func (c *realHTTPClient) FetchData(language string) (*types.GitHubJSONResponse, error) {
+func (c *realHTTPClient) FetchData(ctx context.Context, language string) (*types.GitHubJSONResponse, error) {
 	gitHubURL := fmt.Sprintf(
-		"https://api.github.com/search/repositories?q=stars:>=10000+language:%s&sort=stars&order=desc&per_page=100",
-		language)
+		"%s/search/repositories?q=stars:>=10000+language:%s&sort=stars&order=desc&per_page=100",
+		c.baseURL, language)
 
-	resp, err := c.client.Get(gitHubURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gitHubURL, nil)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New("fetchData: external service status code is not 200")
+	resp, err := c.client.Do(req)
+
+	if err != nil {
+		return nil, err
 	}
 
+	c.observeRateLimit(resp.Header)
+
 	body, err := io.ReadAll(resp.Body)
 	_ = resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("fetchData: external service status code is not 200")
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -62,3 +87,43 @@ func (c *realHTTPClient) FetchData(language string) (*types.GitHubJSONResponse,
 
 	return &ghr, nil
 }
+
+// RateLimit returns the GitHub rate limit reported by the last response
+func (c *realHTTPClient) RateLimit() (types.GitHubRateLimit, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rateLimit == nil {
+		return types.GitHubRateLimit{}, false
+	}
+
+	return *c.rateLimit, true
+}
+
+// observeRateLimit remembers the X-RateLimit headers, responses without them are ignored
+func (c *realHTTPClient) observeRateLimit(h http.Header) {
+	limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rateLimit = &types.GitHubRateLimit{
+		Limit:      limit,
+		Remaining:  remaining,
+		Reset:      time.Unix(reset, 0),
+		ObservedAt: time.Now(),
+	}
+}
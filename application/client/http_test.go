@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetchDataRemembersRateLimit(t *testing.T) {
+	status := http.StatusForbidden
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "10")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1790000000")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"items":[]}`))
+	}))
+	defer server.Close()
+
+	c := NewRealHTTPClient(server.URL, time.Second, nil)
+	reporter := c.(ReportsRateLimit)
+
+	if _, ok := reporter.RateLimit(); ok {
+		t.Fatal("Expected no rate limit before the first response")
+	}
+
+	if _, err := c.FetchData(context.Background(), "go"); err == nil {
+		t.Fatal("Expected error for status 403")
+	}
+
+	limit, ok := reporter.RateLimit()
+
+	if !ok || limit.Limit != 10 || limit.Remaining != 0 || !limit.Reset.Equal(time.Unix(1790000000, 0)) {
+		t.Errorf("Expected rate limit of the failed response. Got %+v", limit)
+	}
+}
@@ -1,34 +1,52 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
 	"github.com/pavbis/repositories-api/application/types"
 )
 
-// Executor is the interface for sql operations
+// Executor is the interface for sql operations, the context variants are used by operations which can be interrupted
 type Executor interface {
 	Exec(query string, args ...interface{}) (sql.Result, error)
 	Query(query string, args ...interface{}) (*sql.Rows, error)
 	QueryRow(query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 type (
-	// RepresentsWriteStorage is a combined interface which holds the both interfaces (PersistsProgrammingLanguage, ProgrammingLanguageDeleter)
+	// RepresentsWriteStorage is a combined interface of all write storage interfaces
 	RepresentsWriteStorage interface {
 		PersistsProgrammingLanguage
+		ProgrammingLanguageDeleter
 		ProgrammingLanguageRepositoryDeleter
+		ProgrammingLanguageRepositoryRestorer
 	}
 
 	// PersistsProgrammingLanguage is interface which represents the programming language persist operation
 	PersistsProgrammingLanguage interface {
-		PersistProgrammingLanguageRepositories(gh *types.GitHubJSONResponse) (types.LanguageID, error)
+		PersistProgrammingLanguageRepositories(ctx context.Context, gh *types.GitHubJSONResponse) (types.LanguageID, error)
 	}
 
-	// ProgrammingLanguageRepositoryDeleter is interface which represents the repository delete operation
+	// ProgrammingLanguageDeleter is interface which represents the programming language delete operation,
+	// the repositories of the language are removed as well and optionally copied into the archive
+	ProgrammingLanguageDeleter interface {
+		RemoveProgrammingLanguage(l *types.ProgrammingLanguage, keepHistory bool) (*types.LanguageRemovalResult, error)
+	}
+
+	// ProgrammingLanguageRepositoryDeleter is interface which represents the repository soft delete operation
 	ProgrammingLanguageRepositoryDeleter interface {
 		RemoveRepository(rn *types.RepositoryID) (*types.RepositoryID, error)
 	}
+
+	// ProgrammingLanguageRepositoryRestorer is interface which represents the restore operation of a deleted repository
+	ProgrammingLanguageRepositoryRestorer interface {
+		RestoreRepository(rn *types.RepositoryID) (*types.RepositoryID, error)
+	}
 )
 
 type (
@@ -38,11 +56,23 @@ type (
 		ProvidesTopRepositoriesPerLanguage
 		ProvidesRepositoryStarsSum
 		ProvidesRepositoriesAndCorrespondingLanguages
+		ProvidesOwner
+		ProvidesTopOwners
+		ProvidesTopics
+		ProvidesRepositoriesForTopic
+		SearchesRepositories
+		ProvidesRepository
+		CountsRepositoriesPerLanguage
+	}
+
+	// CountsRepositoriesPerLanguage represents the count of the repositories of each language
+	CountsRepositoriesPerLanguage interface {
+		CountRepositoriesPerLanguage(ctx context.Context) (map[string]int64, error)
 	}
 
-	// ProvidesRepositoriesForLanguage represents the read operation by programming language
+	// ProvidesRepositoriesForLanguage represents the filtered, sorted and paginated read operation by programming language
 	ProvidesRepositoriesForLanguage interface {
-		ReadRepositoriesForLanguage(l *types.ProgrammingLanguage) ([]byte, error)
+		ReadRepositoriesForLanguage(l *types.ProgrammingLanguage, q *types.RepositoryListQuery) ([]byte, error)
 	}
 
 	// ProvidesTopRepositoriesPerLanguage represents the read top repositories operation
@@ -55,8 +85,124 @@ type (
 		ReadRepositoriesSumsForLanguages() ([]byte, error)
 	}
 
-	// ProvidesRepositoriesAndCorrespondingLanguages represents the languages and repositories
+	// ProvidesRepositoriesAndCorrespondingLanguages represents the paginated languages and repositories
 	ProvidesRepositoriesAndCorrespondingLanguages interface {
-		ReadRepositoriesAndLanguages() ([]byte, error)
+		ReadRepositoriesAndLanguages(p *types.PageRequest) ([]byte, error)
+	}
+
+	// ProvidesOwner represents the owner read operation including the aggregated stats
+	ProvidesOwner interface {
+		ReadOwner(o *types.Owner) ([]byte, error)
+	}
+
+	// ProvidesTopOwners represents the owners ranking read operation, optionally limited to one language
+	ProvidesTopOwners interface {
+		ReadTopOwners(l *types.ProgrammingLanguage) ([]byte, error)
+	}
+
+	// ProvidesTopics represents the topics read operation including the repository counts and stars sums
+	ProvidesTopics interface {
+		ReadTopics() ([]byte, error)
+	}
+
+	// ProvidesRepositoriesForTopic represents the read operation by topic, optionally limited to one language
+	ProvidesRepositoriesForTopic interface {
+		ReadRepositoriesForTopic(t *types.Topic, l *types.ProgrammingLanguage) ([]byte, error)
+	}
+
+	// SearchesRepositories represents the full-text search over repository names and descriptions
+	SearchesRepositories interface {
+		SearchRepositories(q *types.SearchQuery) ([]byte, error)
+	}
+
+	// ProvidesRepository represents the single repository read operation by id or by name
+	ProvidesRepository interface {
+		ReadRepository(rn *types.RepositoryID) ([]byte, error)
+		ReadRepositoryByName(rn *types.RepositoryName) ([]byte, error)
+	}
+)
+
+type (
+	// RepresentsBlocklistStorage is a combined interface of the blocklist rules read and manage operations
+	RepresentsBlocklistStorage interface {
+		ProvidesBlocklistRules
+		ManagesBlocklistRules
+	}
+
+	// ProvidesBlocklistRules represents the read operation of the rules which are applied on import
+	ProvidesBlocklistRules interface {
+		ReadBlocklistRules() ([]types.BlocklistRule, error)
+	}
+
+	// ManagesBlocklistRules represents the blocklist rules add and remove operations
+	ManagesBlocklistRules interface {
+		AddBlocklistRule(rule *types.BlocklistRule) (*types.BlocklistRule, error)
+		RemoveBlocklistRule(id *types.BlocklistRuleID) error
+		// RemoveBlockedRepositories soft deletes the stored repositories matched by the rule and returns their count
+		RemoveBlockedRepositories(rule *types.BlocklistRule) (int64, error)
+	}
+)
+
+type (
+	// RepresentsAPIKeyStorage is a combined interface of the api key management and lookup operations
+	RepresentsAPIKeyStorage interface {
+		ManagesAPIKeys
+		UsesAPIKeys
+	}
+
+	// ManagesAPIKeys represents the api key create, list and revoke operations
+	ManagesAPIKeys interface {
+		CreateAPIKey(k *types.APIKey, secretHash string) (*types.APIKey, error)
+		ReadAPIKeys() ([]types.APIKey, error)
+		RevokeAPIKey(id *types.APIKeyID) error
+	}
+
+	// UsesAPIKeys represents the lookup of an active api key which records its usage
+	UsesAPIKeys interface {
+		UseAPIKey(secretHash string) (*types.APIKey, error)
+	}
+
+	// RepresentsAuditStorage is a combined interface of the audit log write and read operations
+	RepresentsAuditStorage interface {
+		RecordsAuditEntries
+		ReadsAuditLog
+	}
+
+	// RecordsAuditEntries represents the audit entry persist operation
+	RecordsAuditEntries interface {
+		RecordAuditEntry(e *types.AuditEntry) error
+	}
+
+	// ReadsAuditLog represents the filtered and paginated audit log read operation
+	ReadsAuditLog interface {
+		ReadAuditLog(q *types.AuditLogQuery) ([]byte, error)
+	}
+
+	// LimitsRate represents the token bucket shared by the requests of the same client
+	LimitsRate interface {
+		TakeToken(key string, limit types.RateLimit) (types.RateLimitDecision, error)
+	}
+
+	// ExportsRepositories represents the read operation of all stored repositories, optionally of one language,
+	// the repositories are passed to emit one by one ordered by language and stars
+	ExportsRepositories interface {
+		ExportRepositories(ctx context.Context, language string, emit func(types.ExportedRepository) error) error
+	}
+
+	// PrunesData represents the removal of the data which is no longer needed, every operation returns the removed rows.
+	// The soft deleted repositories are kept, they prevent the import from bringing the removed repositories back
+	PrunesData interface {
+		// PruneAPIKeys removes the keys which were revoked or expired before the time
+		PruneAPIKeys(ctx context.Context, before time.Time) (int64, error)
+		PruneRateLimitBuckets(ctx context.Context, idleSince time.Time) (int64, error)
+		PruneAuditLog(ctx context.Context, before time.Time) (int64, error)
+	}
+
+	// ChecksHealth represents the database checks the readiness of the api depends on
+	ChecksHealth interface {
+		Ping(ctx context.Context) error
+		ReadSchemaVersion(ctx context.Context) (types.SchemaVersion, error)
+		// ReadLastSuccessfulImport returns the zero time if no import succeeded yet
+		ReadLastSuccessfulImport(ctx context.Context) (time.Time, error)
 	}
 )
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+// buckets which are idle for longer than this are full again and dropped
+const idleBucketTTL = 24 * time.Hour
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+type inMemoryRateLimitStorage struct {
+	mu       sync.Mutex
+	buckets  map[string]*bucket
+	now      func() time.Time
+	prunedAt time.Time
+}
+
+// NewInMemoryRateLimitStore creates rate limit store which keeps the buckets of this instance only
+func NewInMemoryRateLimitStore() LimitsRate {
+	return &inMemoryRateLimitStorage{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// TakeToken takes one token from the bucket of the key, new buckets start full
+func (s *inMemoryRateLimitStorage) TakeToken(key string, limit types.RateLimit) (types.RateLimitDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.prune(now)
+
+	b, ok := s.buckets[key]
+
+	if !ok {
+		b = &bucket{tokens: limit.Capacity(), updatedAt: now}
+		s.buckets[key] = b
+	}
+
+	var allowed bool
+	b.tokens, allowed = limit.Take(b.tokens, now.Sub(b.updatedAt))
+	b.updatedAt = now
+
+	return limit.Decide(allowed, b.tokens), nil
+}
+
+func (s *inMemoryRateLimitStorage) prune(now time.Time) {
+	if now.Sub(s.prunedAt) < time.Hour {
+		return
+	}
+
+	for key, b := range s.buckets {
+		if now.Sub(b.updatedAt) > idleBucketTTL {
+			delete(s.buckets, key)
+		}
+	}
+
+	s.prunedAt = now
+}
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+func TestInMemoryRateLimitStore(t *testing.T) {
+	now := time.Now()
+	store := &inMemoryRateLimitStorage{buckets: make(map[string]*bucket), now: func() time.Time { return now }}
+	limit := types.RateLimit{Requests: 2, Period: time.Minute}
+
+	for i, expected := range []bool{true, true, false} {
+		decision, _ := store.TakeToken("client", limit)
+
+		if decision.Allowed != expected {
+			t.Errorf("got allowed %t for request %d but expected %t", decision.Allowed, i+1, expected)
+		}
+	}
+
+	if decision, _ := store.TakeToken("other", limit); !decision.Allowed || decision.Remaining != 1 {
+		t.Errorf("got decision %+v but expected other client to have its own bucket", decision)
+	}
+
+	now = now.Add(30 * time.Second)
+
+	if decision, _ := store.TakeToken("client", limit); !decision.Allowed {
+		t.Errorf("got decision %+v but expected refilled token to be taken", decision)
+	}
+
+	now = now.Add(idleBucketTTL + time.Hour)
+	_, _ = store.TakeToken("client", limit)
+
+	if _, ok := store.buckets["other"]; ok {
+		t.Error("expected idle bucket to be pruned")
+	}
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+// ErrAPIKeyNotFound represents error in case the api key is not found or not usable anymore
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+const apiKeyColumns = `"keyId", name, key_prefix, scopes, expires_at, revoked_at, last_used_at, created_at`
+
+type postgresAPIKeyStorage struct {
+	sqlExecutor Executor
+}
+
+// NewPostgresAPIKeyStore creates new api key store instance in valid state
+func NewPostgresAPIKeyStore(e Executor) RepresentsAPIKeyStorage {
+	return &postgresAPIKeyStorage{sqlExecutor: e}
+}
+
+// CreateAPIKey persists the api key metadata together with the hash of its secret
+func (s *postgresAPIKeyStorage) CreateAPIKey(k *types.APIKey, secretHash string) (*types.APIKey, error) {
+	row := s.sqlExecutor.QueryRow(
+		`INSERT INTO api_keys("keyId", name, key_prefix, key_hash, scopes, expires_at)
+		VALUES (uuid_generate_v4(), $1, $2, $3, $4, $5)
+		RETURNING `+apiKeyColumns,
+		k.Name, k.Prefix, secretHash, pq.Array(rolesToStrings(k.Scopes)), k.ExpiresAt)
+
+	return scanAPIKey(row)
+}
+
+// ReadAPIKeys reads the metadata of all api keys including the revoked ones
+func (s *postgresAPIKeyStorage) ReadAPIKeys() ([]types.APIKey, error) {
+	rows, err := s.sqlExecutor.Query(`SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at, "keyId"`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	keys := make([]types.APIKey, 0)
+
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey revokes the api key, revoked keys are kept for reference
+func (s *postgresAPIKeyStorage) RevokeAPIKey(id *types.APIKeyID) error {
+	result, err := s.sqlExecutor.Exec(
+		`UPDATE api_keys SET revoked_at = NOW() WHERE "keyId" = $1 AND revoked_at IS NULL`, id.UUID.String())
+
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affectedRows == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// UseAPIKey looks the active api key up by the hash of its secret and records the usage,
+// the usage is recorded at most once a minute so busy keys do not contend for the row lock on every request
+func (s *postgresAPIKeyStorage) UseAPIKey(secretHash string) (*types.APIKey, error) {
+	row := s.sqlExecutor.QueryRow(
+		`WITH active AS (
+		    SELECT `+apiKeyColumns+`
+		    FROM api_keys
+		    WHERE key_hash = $1
+		      AND revoked_at IS NULL
+		      AND (expires_at IS NULL OR expires_at > NOW())
+		),
+		     used AS (
+		         UPDATE api_keys
+		         SET last_used_at = NOW()
+		         WHERE "keyId" IN (SELECT "keyId" FROM active)
+		           AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+		     )
+		SELECT `+apiKeyColumns+` FROM active`,
+		secretHash)
+
+	key, err := scanAPIKey(row)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	return key, err
+}
+
+// rowScanner is implemented by both sql.Row and sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row rowScanner) (*types.APIKey, error) {
+	var key types.APIKey
+	var scopes []string
+
+	err := row.Scan(
+		&key.UUID, &key.Name, &key.Prefix, pq.Array(&scopes), &key.ExpiresAt, &key.RevokedAt, &key.LastUsedAt, &key.CreatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, types.Role(scope))
+	}
+
+	return &key, nil
+}
+
+func rolesToStrings(roles []types.Role) []string {
+	values := make([]string, 0, len(roles))
+
+	for _, role := range roles {
+		values = append(values, string(role))
+	}
+
+	return values
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"github.com/lib/pq"
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+type postgresAuditStorage struct {
+	sqlExecutor Executor
+}
+
+// NewPostgresAuditStore creates new audit store instance in valid state
+func NewPostgresAuditStore(e Executor) RepresentsAuditStorage {
+	return &postgresAuditStorage{sqlExecutor: e}
+}
+
+// RecordAuditEntry persists the audit entry, the id and the timestamp are assigned by the database
+func (s *postgresAuditStorage) RecordAuditEntry(e *types.AuditEntry) error {
+	targets := e.Targets
+
+	if targets == nil {
+		targets = []string{}
+	}
+
+	return s.sqlExecutor.QueryRow(
+		`INSERT INTO audit_log("entryId", actor, action, targets, request_id, outcome)
+		VALUES (uuid_generate_v4(), $1, $2, $3, NULLIF($4, ''), $5)
+		RETURNING "entryId", created_at`,
+		e.Actor, string(e.Action), pq.Array(targets), e.RequestID, string(e.Outcome)).Scan(&e.UUID, &e.CreatedAt)
+}
+
+// ReadAuditLog reads one page of the audit entries matching the filters from newest to oldest
+func (s *postgresAuditStorage) ReadAuditLog(q *types.AuditLogQuery) ([]byte, error) {
+	var createdAt, entryID interface{}
+
+	if c := q.Page.Cursor; c != nil {
+		createdAt, entryID = c.Value, c.RepositoryID
+	}
+
+	row := s.sqlExecutor.QueryRow(
+		`WITH page AS (
+    SELECT "entryId",
+           actor,
+           action,
+           targets,
+           request_id,
+           outcome,
+           created_at,
+           ROW_NUMBER() OVER (ORDER BY created_at DESC, "entryId" DESC) AS rn
+    FROM audit_log
+    WHERE (NULLIF($2, '') IS NULL OR actor = $2)
+      AND (NULLIF($3, '') IS NULL OR action = $3)
+      AND ($4::timestamptz IS NULL OR created_at >= $4::timestamptz)
+      AND ($5::timestamptz IS NULL OR (created_at, "entryId") < ($5::timestamptz, $6))
+    ORDER BY created_at DESC, "entryId" DESC
+    LIMIT $1 + 1
+)
+SELECT json_build_object(
+               'data', COALESCE((SELECT json_agg(json_build_object(
+                       'entry_id', "entryId",
+                       'actor', actor,
+                       'action', action,
+                       'targets', targets,
+                       'request_id', request_id,
+                       'outcome', outcome,
+                       'created_at', created_at
+                   ) ORDER BY rn)
+                                 FROM page
+                                 WHERE rn <= $1), '[]'),
+               'next', (SELECT json_build_object('v', created_at::text, 'n', '', 'i', "entryId", 's', $7::text)
+                        FROM page
+                        WHERE rn = $1
+                          AND EXISTS(SELECT 1 FROM page WHERE rn > $1))
+           )`,
+		q.Page.Limit, q.Actor, string(q.Action), q.Since, createdAt, entryID, types.AuditCursorSort)
+
+	return scanPageOrFail(row)
+}
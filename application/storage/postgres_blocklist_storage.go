@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+
+	"github.com/lib/pq"
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+var (
+	// ErrBlocklistRuleNotFound represents error in case the blocklist rule is not found
+	ErrBlocklistRuleNotFound = errors.New("blocklist rule not found")
+	// ErrBlocklistRuleExists represents error in case the same rule is already present
+	ErrBlocklistRuleExists = errors.New("blocklist rule already exists")
+)
+
+type postgresBlocklistStorage struct {
+	sqlExecutor Executor
+}
+
+// NewPostgresBlocklistStore creates new blocklist store instance in valid state
+func NewPostgresBlocklistStore(e Executor) RepresentsBlocklistStorage {
+	return &postgresBlocklistStorage{sqlExecutor: e}
+}
+
+// ReadBlocklistRules reads all blocklist rules ordered by creation
+func (s *postgresBlocklistStorage) ReadBlocklistRules() ([]types.BlocklistRule, error) {
+	rows, err := s.sqlExecutor.Query(
+		`SELECT "ruleId", kind, pattern, created_at FROM blocklist_rules ORDER BY created_at, "ruleId"`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	rules := make([]types.BlocklistRule, 0)
+
+	for rows.Next() {
+		var rule types.BlocklistRule
+
+		if err = rows.Scan(&rule.UUID, &rule.Kind, &rule.Pattern, &rule.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// AddBlocklistRule persists the blocklist rule
+func (s *postgresBlocklistStorage) AddBlocklistRule(rule *types.BlocklistRule) (*types.BlocklistRule, error) {
+	err := s.sqlExecutor.QueryRow(
+		`INSERT INTO blocklist_rules("ruleId", kind, pattern)
+		VALUES (uuid_generate_v4(), $1, $2)
+		ON CONFLICT (kind, pattern) DO NOTHING
+		RETURNING "ruleId", created_at;`,
+		string(rule.Kind), rule.Pattern).Scan(&rule.UUID, &rule.CreatedAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrBlocklistRuleExists
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// RemoveBlocklistRule removes the blocklist rule
+func (s *postgresBlocklistStorage) RemoveBlocklistRule(id *types.BlocklistRuleID) error {
+	result, err := s.sqlExecutor.Exec(`DELETE FROM blocklist_rules WHERE "ruleId" = $1`, id.UUID.String())
+
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affectedRows == 0 {
+		return ErrBlocklistRuleNotFound
+	}
+
+	return nil
+}
+
+// blockedRepositoryConditions match the stored repositories like the import matches the fetched ones
+var blockedRepositoryConditions = map[types.BlocklistRuleKind]string{
+	types.NameRule:  `lower(r.full_name) = lower($1)`,
+	types.OwnerRule: `lower(r.owner) = lower($1)`,
+	types.TopicRule: `EXISTS(SELECT 1
+                              FROM repository_topics rt
+                                       JOIN topics tp USING ("topicId")
+                              WHERE rt."repositoryId" = r."repositoryId"
+                                AND lower(tp.name) = lower($1))`,
+}
+
+// RemoveBlockedRepositories soft deletes the stored repositories matched by the rule, so they leave the listings
+// like the import skips them. Regular expressions are matched with the engine of the import against the name
+// and the description, removing the rule later does not restore the repositories.
+func (s *postgresBlocklistStorage) RemoveBlockedRepositories(rule *types.BlocklistRule) (int64, error) {
+	if rule.Kind == types.RegexRule {
+		return s.removeRepositoriesMatchingExpression(rule.Pattern)
+	}
+
+	condition, ok := blockedRepositoryConditions[rule.Kind]
+
+	if !ok {
+		return 0, nil
+	}
+
+	// the condition is one of the whitelisted fragments, the pattern is passed as query argument
+	// #nosec G202
+	result, err := s.sqlExecutor.Exec(
+		`UPDATE repositories r SET deleted_at = NOW() WHERE r.deleted_at IS NULL AND `+condition, rule.Pattern)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (s *postgresBlocklistStorage) removeRepositoriesMatchingExpression(pattern string) (int64, error) {
+	expression, err := regexp.Compile(pattern)
+
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := s.sqlExecutor.Query(
+		`SELECT "repositoryId", full_name, COALESCE(description, '') FROM repositories WHERE deleted_at IS NULL`)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+
+	for rows.Next() {
+		var id, fullName, description string
+
+		if err = rows.Scan(&id, &fullName, &description); err != nil {
+			return 0, err
+		}
+
+		if expression.MatchString(fullName) || expression.MatchString(description) {
+			ids = append(ids, id)
+		}
+	}
+
+	if err = rows.Err(); err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	result, err := s.sqlExecutor.Exec(
+		`UPDATE repositories SET deleted_at = NOW() WHERE "repositoryId" = ANY($1) AND deleted_at IS NULL`, pq.Array(ids))
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
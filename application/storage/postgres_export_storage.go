@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+type postgresExportStorage struct {
+	sqlExecutor Executor
+}
+
+// NewPostgresExportStore creates new export store instance in valid state
+func NewPostgresExportStore(e Executor) ExportsRepositories {
+	return &postgresExportStorage{sqlExecutor: e}
+}
+
+// ExportRepositories streams the repositories which are not deleted, an empty language exports all languages
+func (s *postgresExportStorage) ExportRepositories(ctx context.Context, language string, emit func(types.ExportedRepository) error) error {
+	rows, err := s.sqlExecutor.QueryContext(
+		ctx,
+		`SELECT r."repositoryId", pl.language_name, r.full_name, r.owner, r.stars, r.description, r."createdAt", r.imported_at
+		FROM repositories r
+		         INNER JOIN programming_languages pl ON pl."languageId" = r."languageId"
+		WHERE r.deleted_at IS NULL
+		  AND ($1 = '' OR pl.language_name = $1)
+		ORDER BY pl.language_name, r.stars DESC, r.full_name`,
+		language)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var r types.ExportedRepository
+
+		err = rows.Scan(&r.RepositoryID, &r.Language, &r.FullName, &r.Owner, &r.Stars, &r.Description, &r.CreatedAt, &r.ImportedAt)
+
+		if err != nil {
+			return err
+		}
+
+		if err = emit(r); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+type postgresHealthStorage struct {
+	sqlExecutor Executor
+}
+
+// NewPostgresHealthStore creates new health store instance in valid state
+func NewPostgresHealthStore(e Executor) ChecksHealth {
+	return &postgresHealthStorage{sqlExecutor: e}
+}
+
+// Ping runs a trivial query, unlike sql.DB.Ping it also works on executors which are not connection pools
+func (s *postgresHealthStorage) Ping(ctx context.Context) error {
+	var one int
+
+	return s.sqlExecutor.QueryRowContext(ctx, `SELECT 1`).Scan(&one)
+}
+
+// ReadSchemaVersion reads the version recorded by the migrations, no recorded version is version 0
+func (s *postgresHealthStorage) ReadSchemaVersion(ctx context.Context) (types.SchemaVersion, error) {
+	var v types.SchemaVersion
+
+	err := s.sqlExecutor.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&v.Version, &v.Dirty)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.SchemaVersion{}, nil
+	}
+
+	return v, err
+}
+
+// ReadLastSuccessfulImport reads the time of the latest successful import from the audit log
+func (s *postgresHealthStorage) ReadLastSuccessfulImport(ctx context.Context) (time.Time, error) {
+	var lastImport sql.NullTime
+
+	err := s.sqlExecutor.QueryRowContext(
+		ctx,
+		`SELECT MAX(created_at) FROM audit_log WHERE action = $1 AND outcome = $2`,
+		string(types.LanguageImportAction), string(types.AuditSuccess)).Scan(&lastImport)
+
+	return lastImport.Time, err
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+type postgresMaintenanceStorage struct {
+	sqlExecutor Executor
+}
+
+// NewPostgresMaintenanceStore creates new maintenance store instance in valid state
+func NewPostgresMaintenanceStore(e Executor) PrunesData {
+	return &postgresMaintenanceStorage{sqlExecutor: e}
+}
+
+// PruneAPIKeys removes the revoked and expired api keys, they can not authenticate anymore
+func (s *postgresMaintenanceStorage) PruneAPIKeys(ctx context.Context, before time.Time) (int64, error) {
+	return s.delete(ctx, `DELETE FROM api_keys WHERE revoked_at < $1 OR expires_at < $1`, before)
+}
+
+// PruneRateLimitBuckets removes the buckets which are idle since the time, they would be full again anyway
+func (s *postgresMaintenanceStorage) PruneRateLimitBuckets(ctx context.Context, idleSince time.Time) (int64, error) {
+	return s.delete(ctx, `DELETE FROM rate_limit_buckets WHERE updated_at < $1`, idleSince)
+}
+
+// PruneAuditLog removes the audit entries recorded before the time
+func (s *postgresMaintenanceStorage) PruneAuditLog(ctx context.Context, before time.Time) (int64, error) {
+	return s.delete(ctx, `DELETE FROM audit_log WHERE created_at < $1`, before)
+}
+
+func (s *postgresMaintenanceStorage) delete(ctx context.Context, query string, before time.Time) (int64, error) {
+	result, err := s.sqlExecutor.ExecContext(ctx, query, before)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+type postgresRateLimitStorage struct {
+	sqlExecutor Executor
+}
+
+// NewPostgresRateLimitStore creates rate limit store which shares the buckets between all instances
+func NewPostgresRateLimitStore(e Executor) LimitsRate {
+	return &postgresRateLimitStorage{sqlExecutor: e}
+}
+
+// TakeToken refills the bucket of the key and takes one token in a single statement,
+// the row lock of the upsert serializes concurrent requests of the same client
+func (s *postgresRateLimitStorage) TakeToken(key string, limit types.RateLimit) (types.RateLimitDecision, error) {
+	var allowed bool
+	var tokens float64
+
+	err := s.sqlExecutor.QueryRow(
+		`INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+		VALUES ($1, $2::DOUBLE PRECISION - 1, TRUE, NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			allowed = LEAST($2, b.tokens + EXTRACT(EPOCH FROM NOW() - b.updated_at) * $3) >= 1,
+			tokens = LEAST($2, b.tokens + EXTRACT(EPOCH FROM NOW() - b.updated_at) * $3)
+				- CASE WHEN LEAST($2, b.tokens + EXTRACT(EPOCH FROM NOW() - b.updated_at) * $3) >= 1 THEN 1 ELSE 0 END,
+			updated_at = NOW()
+		RETURNING allowed, tokens`,
+		key, limit.Capacity(), limit.TokensPerSecond()).Scan(&allowed, &tokens)
+
+	if err != nil {
+		return types.RateLimitDecision{}, err
+	}
+
+	return limit.Decide(allowed, tokens), nil
+}
@@ -1,6 +1,17 @@
 package storage
 
-import "github.com/pavbis/repositories-api/application/types"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+// ErrOwnerNotFound represents error in case the owner is not found
+var ErrOwnerNotFound = errors.New("owner not found")
 
 type postgresReadStorage struct {
 	sqlExecutor Executor
@@ -11,27 +22,101 @@ func NewPostgresReadStore(e Executor) RepresentsReadStorage {
 	return &postgresReadStorage{sqlExecutor: e}
 }
 
-// ReadRepositoriesForLanguage reads repositories from database by provided programming language
-func (p *postgresReadStorage) ReadRepositoriesForLanguage(l *types.ProgrammingLanguage) ([]byte, error) {
-	row := p.sqlExecutor.QueryRow(
-		`SELECT COALESCE((SELECT json_strip_nulls(json_agg(r))
-                 FROM (
-                          WITH language AS (
-                              SELECT "languageId"
-                              FROM programming_languages
-                              WHERE language_name = $1
-                          )
-                          SELECT r.full_name,
-                                 r.stars,
-                                 r.description
-                          FROM repositories r
-                          WHERE "languageId" = (SELECT "languageId" FROM language)
-                          ORDER BY stars DESC
-                          LIMIT 1000
-                      ) r), '[]')`,
-		l.Name)
+// repositorySortColumn represents the column the repository list is sorted by and the type of its cursor value
+type repositorySortColumn struct {
+	name     string
+	dataType string
+}
 
-	return scanOrFail(row)
+var repositorySortColumns = map[types.RepositorySortField]repositorySortColumn{
+	types.SortByStars:     {name: "r.stars", dataType: "bigint"},
+	types.SortByName:      {name: "r.full_name", dataType: "text"},
+	types.SortByCreatedAt: {name: `r."createdAt"`, dataType: "timestamptz"},
+}
+
+// ReadRepositoriesForLanguage reads one filtered and sorted page of repositories by provided programming language
+func (p *postgresReadStorage) ReadRepositoriesForLanguage(l *types.ProgrammingLanguage, q *types.RepositoryListQuery) ([]byte, error) {
+	sortColumn, ok := repositorySortColumns[q.Sort]
+
+	if !ok {
+		sortColumn = repositorySortColumns[types.SortByStars]
+	}
+
+	direction, comparison := "DESC", "<"
+
+	if q.Order == types.Ascending {
+		direction, comparison = "ASC", ">"
+	}
+
+	args := []interface{}{l.Name, q.Page.Limit, q.SortKey()}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"pl.language_name = $1", "r.deleted_at IS NULL"}
+
+	if q.MinStars != nil {
+		conditions = append(conditions, "r.stars >= "+addArg(*q.MinStars))
+	}
+
+	if q.MaxStars != nil {
+		conditions = append(conditions, "r.stars <= "+addArg(*q.MaxStars))
+	}
+
+	if q.Owner != "" {
+		conditions = append(conditions, "lower(r.owner) = lower("+addArg(q.Owner)+")")
+	}
+
+	if q.CreatedAfter != nil {
+		conditions = append(conditions, `r."createdAt" > `+addArg(*q.CreatedAfter))
+	}
+
+	if q.CreatedBefore != nil {
+		conditions = append(conditions, `r."createdAt" < `+addArg(*q.CreatedBefore))
+	}
+
+	if c := q.Page.Cursor; c != nil {
+		value := addArg(c.Value) + "::" + sortColumn.dataType
+		conditions = append(conditions, fmt.Sprintf(
+			`(%[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND (r.full_name, r."repositoryId") > (%[4]s, %[5]s)))`,
+			sortColumn.name, comparison, value, addArg(c.FullName), addArg(c.RepositoryID)))
+	}
+
+	orderBy := fmt.Sprintf(`%s %s, r.full_name, r."repositoryId"`, sortColumn.name, direction)
+
+	// the sort column, the direction and the conditions are built from whitelisted fragments only,
+	// all provided values are passed as query arguments
+	// #nosec G201
+	query := fmt.Sprintf(`WITH page AS (
+    SELECT r."repositoryId",
+           r.full_name,
+           r.stars,
+           r.description,
+           %[1]s::text AS sort_value,
+           ROW_NUMBER() OVER (ORDER BY %[3]s) AS rn
+    FROM repositories r
+             JOIN programming_languages pl USING ("languageId")
+    WHERE %[2]s
+    ORDER BY %[3]s
+    LIMIT $2 + 1
+)
+SELECT json_build_object(
+               'data', COALESCE((SELECT json_strip_nulls(json_agg(json_build_object(
+                       'full_name', full_name,
+                       'stars', stars,
+                       'description', description
+                   ) ORDER BY rn))
+                                 FROM page
+                                 WHERE rn <= $2), '[]'),
+               'next', (SELECT json_build_object('v', sort_value, 'n', full_name, 'i', "repositoryId", 's', $3::text)
+                        FROM page
+                        WHERE rn = $2
+                          AND EXISTS(SELECT 1 FROM page WHERE rn > $2))
+           )`,
+		sortColumn.name, strings.Join(conditions, "\n      AND "), orderBy)
+
+	return scanPageOrFail(p.sqlExecutor.QueryRow(query, args...))
 }
 
 // ReadTopRepositoriesPerLanguage reads repositories from database by provided programming language
@@ -46,6 +131,7 @@ func (p *postgresReadStorage) ReadTopRepositoriesPerLanguage() ([]byte, error) {
                                      RANK() OVER (PARTITION BY "languageId" ORDER BY stars DESC) AS rank
                               FROM repositories r
                                        JOIN programming_languages pl USING ("languageId")
+                              WHERE r.deleted_at IS NULL
                           )
                           SELECT rr.language_name,
                                  rr.full_name,
@@ -68,6 +154,7 @@ func (p *postgresReadStorage) ReadRepositoriesSumsForLanguages() ([]byte, error)
                                      SUM(r.stars)     AS stars_sum
                               FROM programming_languages pl
                                        JOIN repositories r USING ("languageId")
+                              WHERE r.deleted_at IS NULL
                               GROUP BY language_id, language_name
                               ORDER BY stars_sum DESC
                           )
@@ -80,27 +167,304 @@ func (p *postgresReadStorage) ReadRepositoriesSumsForLanguages() ([]byte, error)
 	return scanOrFail(row)
 }
 
-func (p *postgresReadStorage) ReadRepositoriesAndLanguages() ([]byte, error) {
+// ReadRepositoriesAndLanguages reads one page of repositories ordered by stars grouped by their languages,
+// languages without repositories on the page are omitted
+func (p *postgresReadStorage) ReadRepositoriesAndLanguages(pr *types.PageRequest) ([]byte, error) {
+	value, fullName, repositoryID := cursorArgs(pr.Cursor)
+	row := p.sqlExecutor.QueryRow(
+		`WITH page AS (
+    SELECT pl."languageId",
+           pl.language_name,
+           r."repositoryId",
+           r.full_name,
+           r.stars,
+           ROW_NUMBER() OVER (ORDER BY r.stars DESC, r.full_name, r."repositoryId") AS rn
+    FROM repositories r
+             JOIN programming_languages pl USING ("languageId")
+    WHERE r.deleted_at IS NULL
+      AND ($2::bigint IS NULL
+        OR r.stars < $2::bigint
+        OR (r.stars = $2::bigint AND (r.full_name, r."repositoryId") > ($3, $4)))
+    ORDER BY r.stars DESC, r.full_name, r."repositoryId"
+    LIMIT $1 + 1
+),
+     languages AS (
+         SELECT "languageId",
+                language_name,
+                json_agg(json_build_object(
+                        'repository_id', "repositoryId",
+                        'repository_name', full_name,
+                        'stars', stars
+                    ) ORDER BY rn) AS repositories
+         FROM page
+         WHERE rn <= $1
+         GROUP BY "languageId", language_name
+     )
+SELECT json_build_object(
+               'data', COALESCE((SELECT json_agg(json_build_object(
+                       'languageId', "languageId",
+                       'language_name', language_name,
+                       'repositories', repositories
+                   ) ORDER BY language_name)
+                                 FROM languages), '[]'),
+               'next', (SELECT json_build_object('v', stars::text, 'n', full_name, 'i', "repositoryId")
+                        FROM page
+                        WHERE rn = $1
+                          AND EXISTS(SELECT 1 FROM page WHERE rn > $1))
+           )`,
+		pr.Limit, value, fullName, repositoryID)
+
+	return scanPageOrFail(row)
+}
+
+// ReadOwner reads the owner and the repository counts and stars sums of its repositories per language
+func (p *postgresReadStorage) ReadOwner(o *types.Owner) ([]byte, error) {
+	row := p.sqlExecutor.QueryRow(
+		`WITH owner AS (
+    SELECT login, type, avatar_url
+    FROM owners
+    WHERE lower(login) = lower($1)
+),
+     owner_languages AS (
+         SELECT pl.language_name,
+                COUNT(r."repositoryId") AS repositories_count,
+                SUM(r.stars)            AS stars_sum
+         FROM repositories r
+                  JOIN programming_languages pl USING ("languageId")
+         WHERE r.owner = (SELECT login FROM owner)
+           AND r.deleted_at IS NULL
+         GROUP BY pl.language_name
+     )
+SELECT json_build_object(
+               'login', o.login,
+               'type', o.type,
+               'avatar_url', o.avatar_url,
+               'repositories_count', (SELECT COALESCE(SUM(repositories_count), 0) FROM owner_languages),
+               'stars_sum', (SELECT COALESCE(SUM(stars_sum), 0) FROM owner_languages),
+               'languages', (SELECT COALESCE(json_agg(ol ORDER BY ol.stars_sum DESC), '[]') FROM owner_languages ol)
+           )
+FROM owner o`,
+		o.Login)
+
+	result, err := scanOrFail(row)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOwnerNotFound
+	}
+
+	return result, err
+}
+
+// ReadTopOwners reads the owners ordered by the stars sum of their repositories
+func (p *postgresReadStorage) ReadTopOwners(l *types.ProgrammingLanguage) ([]byte, error) {
 	row := p.sqlExecutor.QueryRow(
-		`SELECT COALESCE(
-    (SELECT json_agg(json_build_object(
-         'languageId', "languageId",
-         'language_name', language_name,
-         'repositories', (
-             SELECT COALESCE(
-                (SELECT json_agg(json_build_object(
-                     'repository_id', "repositoryId",
-                     'repository_name', full_name,
-                     'stars', stars
-                 ) ORDER BY "full_name")
-                 FROM repositories r
-                 WHERE r."languageId" = pl."languageId"),
-                '[]'
-            )
-         )
-     ) ORDER BY pl.language_name)
-    FROM programming_languages pl),
-'[]')`)
+		`SELECT COALESCE((SELECT json_strip_nulls(json_agg(t))
+                 FROM (
+                          SELECT r.owner                          AS login,
+                                 o.type,
+                                 o.avatar_url,
+                                 COUNT(r."repositoryId")          AS repositories_count,
+                                 SUM(r.stars)                     AS stars_sum,
+                                 COUNT(DISTINCT r."languageId")   AS languages_count
+                          FROM repositories r
+                                   JOIN programming_languages pl USING ("languageId")
+                                   LEFT JOIN owners o ON o.login = r.owner
+                          WHERE r.deleted_at IS NULL
+                            AND ($1 = '' OR pl.language_name = $1)
+                          GROUP BY r.owner, o.type, o.avatar_url
+                          ORDER BY stars_sum DESC, login
+                          LIMIT 100
+                      ) t), '[]')`,
+		l.Name)
 
 	return scanOrFail(row)
 }
+
+// ReadTopics reads all topics with the count and the stars sum of the tagged repositories
+func (p *postgresReadStorage) ReadTopics() ([]byte, error) {
+	row := p.sqlExecutor.QueryRow(
+		`SELECT COALESCE((SELECT json_agg(t)
+                 FROM (
+                          SELECT tp.name                  AS topic,
+                                 COUNT(r."repositoryId")  AS repositories_count,
+                                 SUM(r.stars)             AS stars_sum
+                          FROM topics tp
+                                   JOIN repository_topics rt USING ("topicId")
+                                   JOIN repositories r USING ("repositoryId")
+                          WHERE r.deleted_at IS NULL
+                          GROUP BY tp.name
+                          ORDER BY repositories_count DESC, stars_sum DESC, topic
+                      ) t), '[]')`)
+
+	return scanOrFail(row)
+}
+
+// cursorArgs returns the keyset query arguments of the cursor, they are all NULL for the first page
+func cursorArgs(c *types.Cursor) (value, fullName, repositoryID interface{}) {
+	if c == nil {
+		return nil, nil, nil
+	}
+
+	return c.Value, c.FullName, c.RepositoryID
+}
+
+// ReadRepositoriesForTopic reads the repositories tagged with provided topic ordered by stars
+func (p *postgresReadStorage) ReadRepositoriesForTopic(t *types.Topic, l *types.ProgrammingLanguage) ([]byte, error) {
+	row := p.sqlExecutor.QueryRow(
+		`SELECT COALESCE((SELECT json_strip_nulls(json_agg(r))
+                 FROM (
+                          SELECT r.full_name,
+                                 pl.language_name,
+                                 r.stars,
+                                 r.description
+                          FROM repositories r
+                                   JOIN programming_languages pl USING ("languageId")
+                                   JOIN repository_topics rt USING ("repositoryId")
+                                   JOIN topics tp USING ("topicId")
+                          WHERE tp.name = $1
+                            AND r.deleted_at IS NULL
+                            AND ($2 = '' OR pl.language_name = $2)
+                          ORDER BY r.stars DESC
+                          LIMIT 1000
+                      ) r), '[]')`,
+		t.Name, l.Name)
+
+	return scanOrFail(row)
+}
+
+// SearchRepositories reads the repositories matching the search text ranked by relevance weighted with stars
+func (p *postgresReadStorage) SearchRepositories(q *types.SearchQuery) ([]byte, error) {
+	row := p.sqlExecutor.QueryRow(
+		`SELECT COALESCE((SELECT json_agg(s)
+                 FROM (
+                          WITH query AS (
+                              SELECT websearch_to_tsquery('english', $1) AS tsq
+                          )
+                          SELECT r."repositoryId"                                                     AS repository_id,
+                                 r.full_name,
+                                 pl.language_name,
+                                 r.stars,
+                                 ts_headline('english', r.description, query.tsq,
+                                             'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10')
+                                                                                                      AS snippet,
+                                 round((ts_rank(r.search_vector, query.tsq) * ln(r.stars + 2))::numeric, 6) AS score
+                          FROM repositories r
+                                   JOIN programming_languages pl USING ("languageId"),
+                               query
+                          WHERE r.search_vector @@ query.tsq
+                            AND r.deleted_at IS NULL
+                            AND ($2 = '' OR pl.language_name = $2)
+                          ORDER BY score DESC, r.stars DESC
+                          LIMIT $3
+                      ) s), '[]')`,
+		q.Text, q.LanguageName, q.Limit)
+
+	return scanOrFail(row)
+}
+
+// repositoryDetailQuery reads the repository matching the condition together with the rank in every language
+// the repository was imported for, the most recently imported row is used if the condition matches several rows
+const repositoryDetailQuery = `WITH repository AS (
+    SELECT r."repositoryId",
+           r.full_name,
+           r.owner,
+           r.description,
+           r.stars,
+           r."createdAt"
+    FROM repositories r
+    WHERE %s
+      AND r.deleted_at IS NULL
+    ORDER BY r.imported_at DESC, r.stars DESC
+    LIMIT 1
+),
+     ranked AS (
+         SELECT r."repositoryId",
+                r.full_name,
+                r.stars,
+                r.imported_at,
+                pl.language_name,
+                RANK() OVER (PARTITION BY r."languageId" ORDER BY r.stars DESC) AS rank
+         FROM repositories r
+                  JOIN programming_languages pl USING ("languageId")
+         WHERE r.deleted_at IS NULL
+           AND r."languageId" IN (SELECT "languageId"
+                                  FROM repositories
+                                  WHERE full_name = (SELECT full_name FROM repository))
+     )
+SELECT json_build_object(
+               'repository_id', rp."repositoryId",
+               'full_name', rp.full_name,
+               'owner', rp.owner,
+               'description', rp.description,
+               'stars', rp.stars,
+               'created_at', rp."createdAt",
+               'topics', (SELECT COALESCE(json_agg(tp.name ORDER BY tp.name), '[]')
+                          FROM repository_topics rt
+                                   JOIN topics tp USING ("topicId")
+                          WHERE rt."repositoryId" = rp."repositoryId"),
+               'languages', (SELECT json_agg(json_build_object(
+                       'repository_id', rk."repositoryId",
+                       'language_name', rk.language_name,
+                       'stars', rk.stars,
+                       'rank', rk.rank
+                   ) ORDER BY rk.rank, rk.language_name)
+                             FROM ranked rk
+                             WHERE rk.full_name = rp.full_name),
+               'last_imported_at', (SELECT MAX(rk.imported_at) FROM ranked rk WHERE rk.full_name = rp.full_name)
+           )
+FROM repository rp`
+
+// ReadRepository reads the repository by provided id
+func (p *postgresReadStorage) ReadRepository(rn *types.RepositoryID) ([]byte, error) {
+	row := p.sqlExecutor.QueryRow(fmt.Sprintf(repositoryDetailQuery, `r."repositoryId" = $1`), rn.UUID.String())
+
+	return scanRepositoryOrFail(row)
+}
+
+// ReadRepositoryByName reads the repository by provided owner and name
+func (p *postgresReadStorage) ReadRepositoryByName(rn *types.RepositoryName) ([]byte, error) {
+	row := p.sqlExecutor.QueryRow(fmt.Sprintf(repositoryDetailQuery, `lower(r.full_name) = lower($1)`), rn.FullName())
+
+	return scanRepositoryOrFail(row)
+}
+
+func scanRepositoryOrFail(row *sql.Row) ([]byte, error) {
+	result, err := scanOrFail(row)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRepoNotFound
+	}
+
+	return result, err
+}
+
+// CountRepositoriesPerLanguage counts the repositories of each language, languages without repositories count zero
+func (p *postgresReadStorage) CountRepositoriesPerLanguage(ctx context.Context) (map[string]int64, error) {
+	rows, err := p.sqlExecutor.QueryContext(
+		ctx,
+		`SELECT pl.language_name, COUNT(r."repositoryId")
+		FROM programming_languages pl
+		         LEFT JOIN repositories r ON r."languageId" = pl."languageId" AND r.deleted_at IS NULL
+		GROUP BY pl.language_name`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	counts := make(map[string]int64)
+
+	for rows.Next() {
+		var language string
+		var count int64
+
+		if err = rows.Scan(&language, &count); err != nil {
+			return nil, err
+		}
+
+		counts[language] = count
+	}
+
+	return counts, rows.Err()
+}
@@ -1,13 +1,23 @@
 package storage
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 
+	"github.com/lib/pq"
+
 	"github.com/pavbis/repositories-api/application/types"
 )
 
-// ErrRepoNotFound represents error in case the repository is not found
-var ErrRepoNotFound = errors.New("repository not found")
+var (
+	// ErrRepoNotFound represents error in case the repository is not found
+	ErrRepoNotFound = errors.New("repository not found")
+	// ErrDeletedRepoNotFound represents error in case there is no deleted repository to restore
+	ErrDeletedRepoNotFound = errors.New("deleted repository not found")
+	// ErrLanguageNotFound represents error in case the programming language is not found
+	ErrLanguageNotFound = errors.New("language not found")
+)
 
 type postgresWriteStorage struct {
 	sqlExecutor Executor
@@ -18,35 +28,56 @@ func NewPostgresWriteStore(e Executor) RepresentsWriteStorage {
 	return &postgresWriteStorage{sqlExecutor: e}
 }
 
-// PersistProgrammingLanguageRepositories handles the whole database write operation
-func (s *postgresWriteStorage) PersistProgrammingLanguageRepositories(gh *types.GitHubJSONResponse) (types.LanguageID, error) {
-	languageID, err := s.persistProgrammingLanguage(gh)
+// PersistProgrammingLanguageRepositories handles the whole database write operation. Every repository is
+// persisted on its own, so a cancelled context stops the import between two repositories and a later import
+// completes it.
+func (s *postgresWriteStorage) PersistProgrammingLanguageRepositories(ctx context.Context, gh *types.GitHubJSONResponse) (types.LanguageID, error) {
+	languageID, err := s.persistProgrammingLanguage(ctx, gh)
 
 	if err != nil {
 		return languageID, err
 	}
 
 	for _, repo := range gh.Items {
-		_, err = s.sqlExecutor.Exec(
+		if err = ctx.Err(); err != nil {
+			return languageID, err
+		}
+
+		if err = s.persistOwner(ctx, repo.Owner); err != nil {
+			return languageID, err
+		}
+
+		var repositoryID types.RepositoryID
+
+		err = s.sqlExecutor.QueryRowContext(
+			ctx,
 			`INSERT INTO repositories ("repositoryId", "languageId", full_name, stars, "createdAt", owner, description)
 VALUES (uuid_generate_v4(), $1, $2, $3, $4, $5, $6)
 ON CONFLICT ("languageId", full_name)
     DO UPDATE SET stars       = EXCLUDED.stars,
-                  description = EXCLUDED.description;`,
-			languageID.UUID.String(), repo.FullName, repo.StargazersCount, repo.CreatedAt, repo.Owner.Login, repo.Description)
+                  description = EXCLUDED.description,
+                  imported_at = NOW()
+RETURNING "repositoryId";`,
+			languageID.UUID.String(), repo.FullName, repo.StargazersCount, repo.CreatedAt, repo.Owner.Login, repo.Description).
+			Scan(&repositoryID.UUID)
 
 		if err != nil {
 			return languageID, err
 		}
+
+		if err = s.persistRepositoryTopics(ctx, &repositoryID, repo.Topics); err != nil {
+			return languageID, err
+		}
 	}
 
 	return languageID, nil
 }
 
-func (s *postgresWriteStorage) persistProgrammingLanguage(gh *types.GitHubJSONResponse) (types.LanguageID, error) {
+func (s *postgresWriteStorage) persistProgrammingLanguage(ctx context.Context, gh *types.GitHubJSONResponse) (types.LanguageID, error) {
 	var languageID types.LanguageID
 
-	err := s.sqlExecutor.QueryRow(
+	err := s.sqlExecutor.QueryRowContext(
+		ctx,
 		`INSERT INTO programming_languages("languageId", "language_name")
 		VALUES (uuid_generate_v4(), $1)
 		ON CONFLICT ("language_name") DO UPDATE SET "updated_at" = NOW()
@@ -60,9 +91,124 @@ func (s *postgresWriteStorage) persistProgrammingLanguage(gh *types.GitHubJSONRe
 	return languageID, nil
 }
 
+func (s *postgresWriteStorage) persistOwner(ctx context.Context, o types.Owner) error {
+	_, err := s.sqlExecutor.ExecContext(
+		ctx,
+		`INSERT INTO owners("ownerId", "login", "type", "avatar_url")
+		VALUES (uuid_generate_v4(), $1, $2, $3)
+		ON CONFLICT ("login") DO UPDATE SET "type"       = EXCLUDED.type,
+		                                    "avatar_url" = EXCLUDED.avatar_url,
+		                                    "updated_at" = NOW();`,
+		o.Login, string(o.OwnerType()), o.AvatarURL)
+
+	return err
+}
+
+// persistRepositoryTopics creates missing topics and replaces the topic links of the repository
+func (s *postgresWriteStorage) persistRepositoryTopics(ctx context.Context, rn *types.RepositoryID, topics []string) error {
+	_, err := s.sqlExecutor.ExecContext(
+		ctx,
+		`INSERT INTO topics("topicId", "name")
+		SELECT uuid_generate_v4(), t.name FROM (SELECT DISTINCT unnest($1::text[]) AS name) t
+		ON CONFLICT ("name") DO NOTHING;`,
+		pq.Array(topics))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = s.sqlExecutor.ExecContext(
+		ctx,
+		`DELETE FROM repository_topics rt
+		WHERE rt."repositoryId" = $1
+		  AND rt."topicId" NOT IN (SELECT "topicId" FROM topics WHERE name = ANY ($2::text[]));`,
+		rn.UUID.String(), pq.Array(topics))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = s.sqlExecutor.ExecContext(
+		ctx,
+		`INSERT INTO repository_topics("repositoryId", "topicId")
+		SELECT $1, "topicId" FROM topics WHERE name = ANY ($2::text[])
+		ON CONFLICT DO NOTHING;`,
+		rn.UUID.String(), pq.Array(topics))
+
+	return err
+}
+
+// RemoveProgrammingLanguage removes the programming language together with its repositories in one statement,
+// the repositories are copied into the archive before if the history should be kept
+func (s *postgresWriteStorage) RemoveProgrammingLanguage(
+	l *types.ProgrammingLanguage, keepHistory bool) (*types.LanguageRemovalResult, error) {
+	var removedLanguages int64
+	result := &types.LanguageRemovalResult{LanguageName: l.Name}
+
+	err := s.sqlExecutor.QueryRow(
+		`WITH language AS (
+    SELECT "languageId", language_name
+    FROM programming_languages
+    WHERE language_name = $1
+),
+     archived AS (
+         INSERT INTO repositories_archive ("repositoryId", language_name, full_name, stars, "createdAt", owner,
+                                           description, imported_at, deleted_at)
+             SELECT r."repositoryId",
+                    l.language_name,
+                    r.full_name,
+                    r.stars,
+                    r."createdAt",
+                    r.owner,
+                    r.description,
+                    r.imported_at,
+                    r.deleted_at
+             FROM repositories r
+                      JOIN language l USING ("languageId")
+             WHERE $2
+             RETURNING 1
+     ),
+     removed AS (
+         DELETE FROM programming_languages pl
+             USING language l
+             WHERE pl."languageId" = l."languageId"
+             RETURNING pl."languageId"
+     )
+SELECT (SELECT COUNT(*) FROM removed),
+       (SELECT COUNT(*) FROM repositories r JOIN language l USING ("languageId")),
+       (SELECT COUNT(*) FROM archived)`,
+		l.Name, keepHistory).Scan(&removedLanguages, &result.RemovedRepositories, &result.ArchivedRepositories)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if removedLanguages == 0 {
+		return nil, ErrLanguageNotFound
+	}
+
+	return result, nil
+}
+
+// RemoveRepository hides the repository, the deletion flag is kept on re-import so removals stick
 func (s *postgresWriteStorage) RemoveRepository(rn *types.RepositoryID) (*types.RepositoryID, error) {
-	result, err := s.sqlExecutor.Exec(`DELETE FROM repositories r WHERE r."repositoryId" = $1`, rn.UUID.String())
+	result, err := s.sqlExecutor.Exec(
+		`UPDATE repositories r SET deleted_at = NOW() WHERE r."repositoryId" = $1 AND r.deleted_at IS NULL`,
+		rn.UUID.String())
+
+	return rowAffectedOrFail(rn, result, err, ErrRepoNotFound)
+}
+
+// RestoreRepository reverts the removal of the repository
+func (s *postgresWriteStorage) RestoreRepository(rn *types.RepositoryID) (*types.RepositoryID, error) {
+	result, err := s.sqlExecutor.Exec(
+		`UPDATE repositories r SET deleted_at = NULL WHERE r."repositoryId" = $1 AND r.deleted_at IS NOT NULL`,
+		rn.UUID.String())
+
+	return rowAffectedOrFail(rn, result, err, ErrDeletedRepoNotFound)
+}
 
+func rowAffectedOrFail(rn *types.RepositoryID, result sql.Result, err error, errNotFound error) (*types.RepositoryID, error) {
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +220,7 @@ func (s *postgresWriteStorage) RemoveRepository(rn *types.RepositoryID) (*types.
 	}
 
 	if affectedRows == 0 {
-		return nil, ErrRepoNotFound
+		return nil, errNotFound
 	}
 
 	return rn, nil
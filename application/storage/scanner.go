@@ -1,6 +1,18 @@
 package storage
 
-import "database/sql"
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+// page represents the paginated result built by the database, next holds the sort keys of the last item
+// and is only present if there are more items
+type page struct {
+	Data json.RawMessage `json:"data"`
+	Next *types.Cursor   `json:"next"`
+}
 
 func scanOrFail(r *sql.Row) ([]byte, error) {
 	var jsonResponse []byte
@@ -11,3 +23,27 @@ func scanOrFail(r *sql.Row) ([]byte, error) {
 
 	return jsonResponse, nil
 }
+
+// scanPageOrFail scans the paginated result and wraps it into the response envelope with the encoded next cursor
+func scanPageOrFail(r *sql.Row) ([]byte, error) {
+	jsonResponse, err := scanOrFail(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var p page
+
+	if err = json.Unmarshal(jsonResponse, &p); err != nil {
+		return nil, err
+	}
+
+	envelope := types.Page{Data: p.Data}
+
+	if p.Next != nil {
+		nextCursor := p.Next.Encode()
+		envelope.NextCursor = &nextCursor
+	}
+
+	return json.Marshal(envelope)
+}
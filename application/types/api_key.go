@@ -0,0 +1,68 @@
+package types
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// APIKeySecretPrefix marks the secrets of the api keys, it tells them apart from other bearer tokens
+	APIKeySecretPrefix  = "rk_"
+	apiKeyDisplayLength = len(APIKeySecretPrefix) + 8
+)
+
+// APIKeyID represents the api key uuid
+type APIKeyID struct {
+	UUID uuid.UUID `json:"key_id"`
+}
+
+// APIKey represents the api key metadata, the secret itself is never stored
+type APIKey struct {
+	APIKeyID
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []Role     `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatedAPIKey represents the newly created api key including its secret which is shown only once
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// GenerateAPIKeySecret creates a random api key secret
+func GenerateAPIKeySecret() (string, error) {
+	data := make([]byte, 32)
+
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+
+	return APIKeySecretPrefix + base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// HashAPIKeySecret creates the hash the api key is stored and looked up by
+func HashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeySecretPrefixOf returns the leading characters of the secret which identify the key in listings
+func APIKeySecretPrefixOf(secret string) string {
+	if len(secret) < apiKeyDisplayLength || !strings.HasPrefix(secret, APIKeySecretPrefix) {
+		return ""
+	}
+
+	return secret[:apiKeyDisplayLength]
+}
@@ -0,0 +1,32 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_GenerateAPIKeySecret(t *testing.T) {
+	first, err := GenerateAPIKeySecret()
+
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+
+	second, _ := GenerateAPIKeySecret()
+
+	if !strings.HasPrefix(first, APIKeySecretPrefix) {
+		t.Errorf("got secret %s but expected prefix %s", first, APIKeySecretPrefix)
+	}
+
+	if first == second {
+		t.Errorf("got the same secret %s twice", first)
+	}
+
+	if HashAPIKeySecret(first) == HashAPIKeySecret(second) || len(HashAPIKeySecret(first)) != 64 {
+		t.Errorf("got invalid hashes for secrets %s and %s", first, second)
+	}
+
+	if prefix := APIKeySecretPrefixOf(first); prefix != first[:11] {
+		t.Errorf("got prefix %s but expected %s", prefix, first[:11])
+	}
+}
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction represents the mutating operations which are recorded in the audit log
+type AuditAction string
+
+const (
+	LanguageImportAction    AuditAction = "language.import"
+	LanguageDeleteAction    AuditAction = "language.delete"
+	RepositoryDeleteAction  AuditAction = "repository.delete"
+	RepositoryRestoreAction AuditAction = "repository.restore"
+	BlocklistAddAction      AuditAction = "blocklist.add"
+	BlocklistRemoveAction   AuditAction = "blocklist.remove"
+	APIKeyCreateAction      AuditAction = "api_key.create"
+	APIKeyRevokeAction      AuditAction = "api_key.revoke"
+	DataPruneAction         AuditAction = "data.prune"
+)
+
+// AuditOutcome represents whether the recorded operation succeeded
+type AuditOutcome string
+
+const (
+	AuditSuccess AuditOutcome = "success"
+	AuditFailure AuditOutcome = "failure"
+)
+
+// AuditCursorSort marks the cursors of the audit log so they are not mixed up with repository cursors
+const AuditCursorSort = "audit"
+
+// AuditEntryID represents the audit entry uuid
+type AuditEntryID struct {
+	UUID uuid.UUID `json:"entry_id"`
+}
+
+// AuditEntry represents who executed which operation on which targets and how it ended
+type AuditEntry struct {
+	AuditEntryID
+	Actor     string       `json:"actor"`
+	Action    AuditAction  `json:"action"`
+	Targets   []string     `json:"targets"`
+	RequestID string       `json:"request_id,omitempty"`
+	Outcome   AuditOutcome `json:"outcome"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// AuditLogQuery represents the filters of the audit log, entries are ordered from newest to oldest
+type AuditLogQuery struct {
+	Actor  string
+	Action AuditAction
+	Since  *time.Time
+	Page   PageRequest
+}
@@ -0,0 +1,46 @@
+package types
+
+// Role represents the permission level of an authenticated client
+type Role string
+
+const (
+	ReaderRole   Role = "reader"
+	ImporterRole Role = "importer"
+	AdminRole    Role = "admin"
+)
+
+// roleLevels orders the roles, every role includes the permissions of the roles below it
+var roleLevels = map[Role]int{
+	ReaderRole:   1,
+	ImporterRole: 2,
+	AdminRole:    3,
+}
+
+// IsValid executes the check on provided value validity
+func (r Role) IsValid() bool {
+	_, ok := roleLevels[r]
+
+	return ok
+}
+
+// Grants reports whether the role includes the permissions of the required role
+func (r Role) Grants(required Role) bool {
+	return r.IsValid() && roleLevels[r] >= roleLevels[required]
+}
+
+// Principal represents the authenticated client and its roles
+type Principal struct {
+	Name  string
+	Roles []Role
+}
+
+// HasRole reports whether any role of the principal grants the required role
+func (p *Principal) HasRole(required Role) bool {
+	for _, role := range p.Roles {
+		if role.Grants(required) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,69 @@
+package types
+
+import (
+	"testing"
+)
+
+func Test_RoleGrants(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           Role
+		required       Role
+		expectedResult bool
+	}{
+		{
+			name:           "Test reader requires reader",
+			role:           ReaderRole,
+			required:       ReaderRole,
+			expectedResult: true,
+		},
+		{
+			name:           "Test reader requires importer",
+			role:           ReaderRole,
+			required:       ImporterRole,
+			expectedResult: false,
+		},
+		{
+			name:           "Test importer requires reader",
+			role:           ImporterRole,
+			required:       ReaderRole,
+			expectedResult: true,
+		},
+		{
+			name:           "Test importer requires admin",
+			role:           ImporterRole,
+			required:       AdminRole,
+			expectedResult: false,
+		},
+		{
+			name:           "Test admin requires importer",
+			role:           AdminRole,
+			required:       ImporterRole,
+			expectedResult: true,
+		},
+		{
+			name:           "Test invalid role requires reader",
+			role:           Role("guest"),
+			required:       ReaderRole,
+			expectedResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		if result := test.role.Grants(test.required); result != test.expectedResult {
+			t.Errorf("for role test '%s', got result %t but expected %t", test.name, result, test.expectedResult)
+		}
+	}
+}
+
+func Test_PrincipalHasRole(t *testing.T) {
+	principal := &Principal{Name: "test", Roles: []Role{ReaderRole, ImporterRole}}
+
+	if !principal.HasRole(ImporterRole) {
+		t.Errorf("expected principal with roles %v to have role %s", principal.Roles, ImporterRole)
+	}
+
+	if principal.HasRole(AdminRole) {
+		t.Errorf("expected principal with roles %v not to have role %s", principal.Roles, AdminRole)
+	}
+}
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BlocklistRuleKind represents the allowed kinds of blocklist rules
+type BlocklistRuleKind string
+
+const (
+	NameRule  BlocklistRuleKind = "name"
+	OwnerRule BlocklistRuleKind = "owner"
+	RegexRule BlocklistRuleKind = "regex"
+	TopicRule BlocklistRuleKind = "topic"
+)
+
+// BlocklistRuleID represents the blocklist rule uuid
+type BlocklistRuleID struct {
+	UUID uuid.UUID `json:"rule_id"`
+}
+
+// BlocklistRule represents the rule which excludes matching repositories from the import,
+// regex rules are matched against the repository name and description
+type BlocklistRule struct {
+	BlocklistRuleID
+	Kind      BlocklistRuleKind `json:"kind"`
+	Pattern   string            `json:"pattern"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// ImportResult represents the outcome of the language repositories import
+type ImportResult struct {
+	LanguageID
+	Pages                 int `json:"pages"`
+	FetchedRepositories   int `json:"fetched_repositories"`
+	PersistedRepositories int `json:"persisted_repositories"`
+	FilteredRepositories  int `json:"filtered_repositories"`
+}
@@ -0,0 +1,15 @@
+package types
+
+import "time"
+
+// ExportedRepository represents one stored repository in the exports
+type ExportedRepository struct {
+	RepositoryID string    `json:"repository_id"`
+	Language     string    `json:"language"`
+	FullName     string    `json:"full_name"`
+	Owner        string    `json:"owner"`
+	Stars        int64     `json:"stars"`
+	Description  string    `json:"description"`
+	CreatedAt    time.Time `json:"created_at"`
+	ImportedAt   time.Time `json:"imported_at"`
+}
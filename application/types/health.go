@@ -0,0 +1,34 @@
+package types
+
+import "time"
+
+// HealthStatus represents the state of one dependency checked by the readiness probe
+type HealthStatus string
+
+const (
+	// HealthUp means the dependency works as expected
+	HealthUp HealthStatus = "up"
+	// HealthDown means the dependency fails or is in an unexpected state
+	HealthDown HealthStatus = "down"
+	// HealthUnknown means the state could not be determined yet
+	HealthUnknown HealthStatus = "unknown"
+)
+
+// SchemaVersion represents the applied migration version, dirty if a migration failed halfway
+type SchemaVersion struct {
+	Version uint
+	Dirty   bool
+}
+
+// GitHubRateLimit represents the rate limit of the GitHub api as reported by its last response
+type GitHubRateLimit struct {
+	Limit      int
+	Remaining  int
+	Reset      time.Time
+	ObservedAt time.Time
+}
+
+// Exhausted reports whether no request is left until the rate limit resets
+func (l GitHubRateLimit) Exhausted(now time.Time) bool {
+	return l.Remaining <= 0 && now.Before(l.Reset)
+}
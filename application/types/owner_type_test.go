@@ -0,0 +1,38 @@
+package types
+
+import (
+	"testing"
+)
+
+func Test_OwnerType(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedResult OwnerType
+	}{
+		{
+			name:           "Test with organization",
+			input:          "Organization",
+			expectedResult: OrganizationOwner,
+		},
+		{
+			name:           "Test with user",
+			input:          "User",
+			expectedResult: UserOwner,
+		},
+		{
+			name:           "Test with unknown type",
+			input:          "Bot",
+			expectedResult: UserOwner,
+		},
+	}
+
+	for _, test := range tests {
+		owner := Owner{Login: "test", Type: test.input}
+		result := owner.OwnerType()
+
+		if result != test.expectedResult {
+			t.Errorf("for owner type test '%s', got result %s but expected %s", test.name, result, test.expectedResult)
+		}
+	}
+}
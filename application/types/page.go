@@ -0,0 +1,83 @@
+package types
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMalformedCursor represents error in case the cursor can not be decoded
+var ErrMalformedCursor = errors.New("malformed cursor")
+
+// Cursor represents the sort keys of the last item of a page
+type Cursor struct {
+	Value        string `json:"v"`
+	FullName     string `json:"n"`
+	RepositoryID string `json:"i"`
+	Sort         string `json:"s,omitempty"`
+}
+
+// Encode encodes the cursor into the opaque url safe representation
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor decodes the opaque cursor representation created by Encode
+func DecodeCursor(encoded string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+
+	if err != nil {
+		return nil, ErrMalformedCursor
+	}
+
+	var c Cursor
+
+	if err = json.Unmarshal(data, &c); err != nil || c.Value == "" || c.RepositoryID == "" {
+		return nil, ErrMalformedCursor
+	}
+
+	return &c, nil
+}
+
+// cursorTimestampLayouts are the text representations of postgres timestamps, the fraction of a second is optional
+var cursorTimestampLayouts = []string{"2006-01-02 15:04:05-07", "2006-01-02 15:04:05-07:00", time.RFC3339Nano}
+
+// HasValidValue reports whether the value can be compared with the column of the cursor's sort,
+// the cursors of the language list carry no sort and hold stars
+func (c *Cursor) HasValidValue() bool {
+	field, _, _ := strings.Cut(c.Sort, ":")
+
+	switch {
+	case c.Sort == "" || RepositorySortField(field) == SortByStars:
+		_, err := strconv.ParseInt(c.Value, 10, 64)
+
+		return err == nil
+	case c.Sort == AuditCursorSort || RepositorySortField(field) == SortByCreatedAt:
+		for _, layout := range cursorTimestampLayouts {
+			if _, err := time.Parse(layout, c.Value); err == nil {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// PageRequest represents the requested page size and the position to continue from
+type PageRequest struct {
+	Limit  int
+	Cursor *Cursor
+}
+
+// Page represents the paginated response envelope
+type Page struct {
+	Data       json.RawMessage `json:"data"`
+	NextCursor *string         `json:"next_cursor"`
+}
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_CursorRoundTrip(t *testing.T) {
+	cursor := &Cursor{Value: "76744", FullName: "golang/go", RepositoryID: "34ffdec9-26e4-4c2f-b9ae-4dc9cb647dc5"}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+
+	if err != nil {
+		t.Fatalf("got unexpected error %v", err)
+	}
+
+	if *decoded != *cursor {
+		t.Errorf("got result %v but expected %v", decoded, cursor)
+	}
+}
+
+func Test_DecodeMalformedCursor(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "Test with invalid base64",
+			input: "not a cursor!",
+		},
+		{
+			name:  "Test with invalid json",
+			input: "bm90IGpzb24",
+		},
+		{
+			name:  "Test with missing keys",
+			input: (&Cursor{FullName: "golang/go"}).Encode(),
+		},
+	}
+
+	for _, test := range tests {
+		if _, err := DecodeCursor(test.input); !errors.Is(err, ErrMalformedCursor) {
+			t.Errorf("for cursor test '%s', got error %v but expected %v", test.name, err, ErrMalformedCursor)
+		}
+	}
+}
+
+func Test_CursorHasValidValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		cursor   Cursor
+		expected bool
+	}{
+		{name: "Test stars of the language list", cursor: Cursor{Value: "76744"}, expected: true},
+		{name: "Test name instead of stars", cursor: Cursor{Value: "golang/go"}, expected: false},
+		{name: "Test stars sort", cursor: Cursor{Value: "12", Sort: "stars:asc"}, expected: true},
+		{name: "Test text for stars sort", cursor: Cursor{Value: "many", Sort: "stars:desc"}, expected: false},
+		{name: "Test name sort", cursor: Cursor{Value: "golang/go", Sort: "name:asc"}, expected: true},
+		{name: "Test created at sort", cursor: Cursor{Value: "2024-03-01 10:15:30.123456+00", Sort: "created_at:desc"}, expected: true},
+		{name: "Test created at with minutes offset", cursor: Cursor{Value: "2024-03-01 10:15:30+05:30", Sort: "created_at:asc"}, expected: true},
+		{name: "Test invalid created at", cursor: Cursor{Value: "yesterday", Sort: "created_at:desc"}, expected: false},
+		{name: "Test audit log", cursor: Cursor{Value: "2024-03-01 10:15:30.5+00", Sort: AuditCursorSort}, expected: true},
+		{name: "Test invalid audit log", cursor: Cursor{Value: "76744", Sort: AuditCursorSort}, expected: false},
+	}
+
+	for _, test := range tests {
+		if got := test.cursor.HasValidValue(); got != test.expected {
+			t.Errorf("for cursor test '%s', got %v but expected %v", test.name, got, test.expected)
+		}
+	}
+}
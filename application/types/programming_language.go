@@ -12,6 +12,13 @@ type LanguageID struct {
 	UUID uuid.UUID `json:"language_id"`
 }
 
+// LanguageRemovalResult represents the outcome of the programming language removal
+type LanguageRemovalResult struct {
+	LanguageName         string `json:"language_name"`
+	RemovedRepositories  int64  `json:"removed_repositories"`
+	ArchivedRepositories int64  `json:"archived_repositories"`
+}
+
 // SupportedProgrammingLanguageEnum represents the allowed languages
 type SupportedProgrammingLanguageEnum string
 
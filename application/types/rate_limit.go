@@ -0,0 +1,99 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit allows Requests per Period as token bucket, a full bucket allows bursts of Requests
+type RateLimit struct {
+	Requests int64
+	Period   time.Duration
+}
+
+// RateLimitDecision is the outcome of taking a token from the client's bucket
+type RateLimitDecision struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, zero if allowed
+	RetryAfter time.Duration
+}
+
+// ParseRateLimit parses limits like 30/1m, zero requests disables the limit
+func ParseRateLimit(value string) (RateLimit, error) {
+	requests, period, ok := strings.Cut(strings.TrimSpace(value), "/")
+
+	if !ok {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q, expected requests/period", value)
+	}
+
+	n, err := strconv.ParseInt(requests, 10, 64)
+
+	if err != nil || n < 0 {
+		return RateLimit{}, fmt.Errorf("invalid number of requests in rate limit %q", value)
+	}
+
+	d, err := time.ParseDuration(period)
+
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid period in rate limit %q", value)
+	}
+
+	return RateLimit{Requests: n, Period: d}, nil
+}
+
+// IsUnlimited reports whether the limit is disabled
+func (l RateLimit) IsUnlimited() bool {
+	return l.Requests == 0
+}
+
+// Capacity is the number of tokens of a full bucket
+func (l RateLimit) Capacity() float64 {
+	return float64(l.Requests)
+}
+
+// TokensPerSecond is the refill rate of the bucket
+func (l RateLimit) TokensPerSecond() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Take refills the bucket for the elapsed time and takes one token if available
+func (l RateLimit) Take(tokens float64, elapsed time.Duration) (float64, bool) {
+	tokens = math.Min(l.Capacity(), tokens+elapsed.Seconds()*l.TokensPerSecond())
+
+	if tokens < 1 {
+		return tokens, false
+	}
+
+	return tokens - 1, true
+}
+
+// Decide describes the state of the bucket holding the provided tokens after the request
+func (l RateLimit) Decide(allowed bool, tokens float64) RateLimitDecision {
+	decision := RateLimitDecision{
+		Allowed:   allowed,
+		Limit:     l.Requests,
+		Remaining: int64(math.Max(0, math.Floor(tokens))),
+		Reset:     l.durationFor(l.Capacity() - tokens),
+	}
+
+	if !allowed {
+		decision.RetryAfter = l.durationFor(1 - tokens)
+	}
+
+	return decision
+}
+
+func (l RateLimit) durationFor(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+
+	return time.Duration(math.Ceil(tokens / l.TokensPerSecond() * float64(time.Second)))
+}
@@ -0,0 +1,49 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	limit, err := ParseRateLimit("30/1m")
+
+	if err != nil || limit.Requests != 30 || limit.Period != time.Minute {
+		t.Errorf("got rate limit %v and error %v but expected 30 requests per minute", limit, err)
+	}
+
+	for _, value := range []string{"30", "x/1m", "-1/1m", "30/x", "30/0s"} {
+		if _, err = ParseRateLimit(value); err == nil {
+			t.Errorf("expected error for rate limit %q. Got none", value)
+		}
+	}
+}
+
+func TestRateLimitTake(t *testing.T) {
+	limit := RateLimit{Requests: 2, Period: 2 * time.Second}
+
+	tokens, allowed := limit.Take(limit.Capacity(), 0)
+	if !allowed || tokens != 1 {
+		t.Errorf("got %f tokens and allowed %t but expected 1 token and allowed", tokens, allowed)
+	}
+
+	tokens, allowed = limit.Take(tokens, 0)
+	if !allowed || tokens != 0 {
+		t.Errorf("got %f tokens and allowed %t but expected 0 tokens and allowed", tokens, allowed)
+	}
+
+	tokens, allowed = limit.Take(tokens, 500*time.Millisecond)
+	if allowed || tokens != 0.5 {
+		t.Errorf("got %f tokens and allowed %t but expected 0.5 tokens and denied", tokens, allowed)
+	}
+
+	decision := limit.Decide(allowed, tokens)
+	if decision.Remaining != 0 || decision.RetryAfter != 500*time.Millisecond || decision.Reset != 1500*time.Millisecond {
+		t.Errorf("got unexpected decision %+v", decision)
+	}
+
+	tokens, allowed = limit.Take(tokens, time.Hour)
+	if !allowed || tokens != 1 {
+		t.Errorf("got %f tokens and allowed %t but expected refill to be capped at capacity", tokens, allowed)
+	}
+}
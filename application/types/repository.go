@@ -1,10 +1,33 @@
 package types
 
-import "github.com/google/uuid"
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// OwnerType represents the kind of repository owner
+type OwnerType string
+
+const (
+	UserOwner         OwnerType = "user"
+	OrganizationOwner OwnerType = "organization"
+)
 
 // Owner represents the owner structure
 type Owner struct {
-	Login string
+	Login     string
+	Type      string
+	AvatarURL string `json:"avatar_url"`
+}
+
+// OwnerType maps the GitHub owner type to the stored owner type
+func (o Owner) OwnerType() OwnerType {
+	if strings.EqualFold(o.Type, "Organization") {
+		return OrganizationOwner
+	}
+
+	return UserOwner
 }
 
 // GitHubRepository represents the repository structure
@@ -14,6 +37,7 @@ type GitHubRepository struct {
 	Description     string
 	CreatedAt       string `json:"created_at"`
 	StargazersCount int    `json:"stargazers_count"`
+	Topics          []string
 }
 
 // GitHubJSONResponse represents the json structure of the external api response
@@ -22,6 +46,22 @@ type GitHubJSONResponse struct {
 	Items []GitHubRepository
 }
 
+// Topic represents the repository topic structure
+type Topic struct {
+	Name string
+}
+
+// RepositoryName represents the repository name as shown on GitHub
+type RepositoryName struct {
+	Owner string
+	Name  string
+}
+
+// FullName returns the name in owner/name notation
+func (rn RepositoryName) FullName() string {
+	return rn.Owner + "/" + rn.Name
+}
+
 // RepositoryID represents the repository uuid
 type RepositoryID struct {
 	UUID uuid.UUID `json:"repository_id"`
@@ -0,0 +1,37 @@
+package types
+
+import "time"
+
+// RepositorySortField represents the allowed sort fields of the repository list
+type RepositorySortField string
+
+const (
+	SortByStars     RepositorySortField = "stars"
+	SortByName      RepositorySortField = "name"
+	SortByCreatedAt RepositorySortField = "created_at"
+)
+
+// SortOrder represents the allowed sort directions
+type SortOrder string
+
+const (
+	Ascending  SortOrder = "asc"
+	Descending SortOrder = "desc"
+)
+
+// RepositoryListQuery represents the filters, the sorting and the page of the repository list
+type RepositoryListQuery struct {
+	MinStars      *int64
+	MaxStars      *int64
+	Owner         string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Sort          RepositorySortField
+	Order         SortOrder
+	Page          PageRequest
+}
+
+// SortKey identifies the sorting the page cursors of the query are bound to
+func (q *RepositoryListQuery) SortKey() string {
+	return string(q.Sort) + ":" + string(q.Order)
+}
@@ -0,0 +1,8 @@
+package types
+
+// SearchQuery represents the full-text search over repository names and descriptions
+type SearchQuery struct {
+	Text         string
+	LanguageName string
+	Limit        int
+}
@@ -0,0 +1,80 @@
+package writemodel
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+// blocklist matches repositories against the compiled blocklist rules
+type blocklist struct {
+	names       map[string]struct{}
+	owners      map[string]struct{}
+	topics      map[string]struct{}
+	expressions []*regexp.Regexp
+}
+
+// newBlocklist compiles the rules, regex rules which do not compile are ignored
+func newBlocklist(rules []types.BlocklistRule) *blocklist {
+	b := &blocklist{
+		names:  make(map[string]struct{}),
+		owners: make(map[string]struct{}),
+		topics: make(map[string]struct{}),
+	}
+
+	for _, rule := range rules {
+		switch rule.Kind {
+		case types.NameRule:
+			b.names[strings.ToLower(rule.Pattern)] = struct{}{}
+		case types.OwnerRule:
+			b.owners[strings.ToLower(rule.Pattern)] = struct{}{}
+		case types.TopicRule:
+			b.topics[strings.ToLower(rule.Pattern)] = struct{}{}
+		case types.RegexRule:
+			if expression, err := regexp.Compile(rule.Pattern); err == nil {
+				b.expressions = append(b.expressions, expression)
+			}
+		}
+	}
+
+	return b
+}
+
+// matches reports whether any rule matches the repository
+func (b *blocklist) matches(repo types.GitHubRepository) bool {
+	if _, ok := b.names[strings.ToLower(repo.FullName)]; ok {
+		return true
+	}
+
+	if _, ok := b.owners[strings.ToLower(repo.Owner.Login)]; ok {
+		return true
+	}
+
+	for _, topic := range repo.Topics {
+		if _, ok := b.topics[strings.ToLower(topic)]; ok {
+			return true
+		}
+	}
+
+	for _, expression := range b.expressions {
+		if expression.MatchString(repo.FullName) || expression.MatchString(repo.Description) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filter returns the repositories which are not matched by any rule and the count of the matched ones
+func (b *blocklist) filter(repos []types.GitHubRepository) ([]types.GitHubRepository, int) {
+	allowed := make([]types.GitHubRepository, 0, len(repos))
+
+	for _, repo := range repos {
+		if !b.matches(repo) {
+			allowed = append(allowed, repo)
+		}
+	}
+
+	return allowed, len(repos) - len(allowed)
+}
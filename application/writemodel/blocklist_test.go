@@ -0,0 +1,56 @@
+package writemodel
+
+import (
+	"testing"
+
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+func Test_BlocklistMatches(t *testing.T) {
+	rules := []types.BlocklistRule{
+		{Kind: types.NameRule, Pattern: "Avelino/Awesome-Go"},
+		{Kind: types.OwnerRule, Pattern: "astaxie"},
+		{Kind: types.RegexRule, Pattern: `(?i)\bebook\b`},
+		{Kind: types.TopicRule, Pattern: "awesome-list"},
+	}
+
+	tests := []struct {
+		name           string
+		input          types.GitHubRepository
+		expectedResult bool
+	}{
+		{
+			name:           "Test with name rule",
+			input:          types.GitHubRepository{FullName: "avelino/awesome-go"},
+			expectedResult: true,
+		},
+		{
+			name:           "Test with owner rule",
+			input:          types.GitHubRepository{FullName: "astaxie/build-web-application-with-golang", Owner: types.Owner{Login: "astaxie"}},
+			expectedResult: true,
+		},
+		{
+			name:           "Test with regex rule on description",
+			input:          types.GitHubRepository{FullName: "someone/book", Description: "An open source eBook"},
+			expectedResult: true,
+		},
+		{
+			name:           "Test with topic rule",
+			input:          types.GitHubRepository{FullName: "someone/list", Topics: []string{"go", "awesome-list"}},
+			expectedResult: true,
+		},
+		{
+			name:           "Test without matching rule",
+			input:          types.GitHubRepository{FullName: "golang/go", Owner: types.Owner{Login: "golang"}, Description: "The Go programming language"},
+			expectedResult: false,
+		},
+	}
+
+	b := newBlocklist(rules)
+
+	for _, test := range tests {
+		if result := b.matches(test.input); result != test.expectedResult {
+			t.Errorf("for blocklist test '%s', got result %t but expected %t", test.name, result, test.expectedResult)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package writemodel
 
 import (
+	"context"
+
 	"github.com/pavbis/repositories-api/application/client"
 	"github.com/pavbis/repositories-api/application/storage"
 	"github.com/pavbis/repositories-api/application/types"
@@ -8,35 +10,51 @@ import (
 
 // WriteOperationsHandler handles data between external and internal storage
 type WriteOperationsHandler interface {
-	HandleRepositories(pl *types.ProgrammingLanguage) (types.LanguageID, error)
+	HandleRepositories(ctx context.Context, pl *types.ProgrammingLanguage) (types.ImportResult, error)
 }
 
 type writeLanguageRepositoriesCommandHandler struct {
 	client  client.HTTPClient
 	storage storage.PersistsProgrammingLanguage
+	rules   storage.ProvidesBlocklistRules
 }
 
 // NewWriteLanguageRepositoriesCommandHandler creates new instance of writeLanguageRepositoriesCommandHandler in valid state
 func NewWriteLanguageRepositoriesCommandHandler(
-	c client.HTTPClient, s storage.PersistsProgrammingLanguage) WriteOperationsHandler {
-	return &writeLanguageRepositoriesCommandHandler{c, s}
+	c client.HTTPClient, s storage.PersistsProgrammingLanguage, r storage.ProvidesBlocklistRules) WriteOperationsHandler {
+	return &writeLanguageRepositoriesCommandHandler{c, s, r}
 }
 
-// HandleRepositories fetches the data and persists it
-func (ch *writeLanguageRepositoriesCommandHandler) HandleRepositories(pl *types.ProgrammingLanguage) (types.LanguageID, error) {
-	var languageID types.LanguageID
+// HandleRepositories fetches the data, removes the blocklisted repositories and persists the rest,
+// the import stops when the context is cancelled
+func (ch *writeLanguageRepositoriesCommandHandler) HandleRepositories(ctx context.Context, pl *types.ProgrammingLanguage) (types.ImportResult, error) {
+	var result types.ImportResult
+
+	respData, err := ch.client.FetchData(ctx, pl.Name)
+
+	if err != nil {
+		return result, err
+	}
+
+	// the search returns the most starred repositories on a single page
+	result.Pages = 1
+	result.FetchedRepositories = len(respData.Items)
 
-	respData, err := ch.client.FetchData(pl.Name)
+	rules, err := ch.rules.ReadBlocklistRules()
 
 	if err != nil {
-		return languageID, err
+		return result, err
 	}
 
-	languageID, err = ch.storage.PersistProgrammingLanguageRepositories(respData)
+	respData.Items, result.FilteredRepositories = newBlocklist(rules).filter(respData.Items)
+
+	result.LanguageID, err = ch.storage.PersistProgrammingLanguageRepositories(ctx, respData)
 
 	if err != nil {
-		return languageID, err
+		return result, err
 	}
 
-	return languageID, nil
+	result.PersistedRepositories = len(respData.Items)
+
+	return result, nil
 }
@@ -1,6 +1,7 @@
 package writemodel
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -19,21 +20,21 @@ var ErrStorage = errors.New("storage error")
 // FakeHTTPClientWithError is fake client which provokes ErrorWhileFetchingData
 type FakeHTTPClientWithError struct{}
 
-func (f *FakeHTTPClientWithError) FetchData(language string) (*types.GitHubJSONResponse, error) {
+func (f *FakeHTTPClientWithError) FetchData(ctx context.Context, language string) (*types.GitHubJSONResponse, error) {
 	return nil, ErrorWhileFetchingData
 }
 
 // FakeHTTPClientWithoutError simulates valid client response
 type FakeHTTPClientWithoutError struct{}
 
-func (f *FakeHTTPClientWithoutError) FetchData(language string) (*types.GitHubJSONResponse, error) {
+func (f *FakeHTTPClientWithoutError) FetchData(ctx context.Context, language string) (*types.GitHubJSONResponse, error) {
 	return &types.GitHubJSONResponse{}, nil
 }
 
 // StorageWhichReturnsLanguageID simulates valid storage result
 type StorageWhichReturnsLanguageID struct{}
 
-func (s *StorageWhichReturnsLanguageID) PersistProgrammingLanguageRepositories(gh *types.GitHubJSONResponse) (types.LanguageID, error) {
+func (s *StorageWhichReturnsLanguageID) PersistProgrammingLanguageRepositories(ctx context.Context, gh *types.GitHubJSONResponse) (types.LanguageID, error) {
 	newUUID := uuid.MustParse(languageID)
 
 	return types.LanguageID{UUID: newUUID}, nil
@@ -42,17 +43,59 @@ func (s *StorageWhichReturnsLanguageID) PersistProgrammingLanguageRepositories(g
 // StorageWhichReturnsError simulates storage error
 type StorageWhichReturnsError struct{}
 
-func (s *StorageWhichReturnsError) PersistProgrammingLanguageRepositories(gh *types.GitHubJSONResponse) (types.LanguageID, error) {
+func (s *StorageWhichReturnsError) PersistProgrammingLanguageRepositories(ctx context.Context, gh *types.GitHubJSONResponse) (types.LanguageID, error) {
 	return types.LanguageID{}, ErrStorage
 }
 
+// EmptyBlocklist simulates the blocklist without any rules
+type EmptyBlocklist struct{}
+
+func (b *EmptyBlocklist) ReadBlocklistRules() ([]types.BlocklistRule, error) {
+	return nil, nil
+}
+
+// BlocklistWhichReturnsError simulates blocklist storage error
+type BlocklistWhichReturnsError struct{}
+
+func (b *BlocklistWhichReturnsError) ReadBlocklistRules() ([]types.BlocklistRule, error) {
+	return nil, ErrStorage
+}
+
+// FakeHTTPClientWithRepositories simulates valid client response with repositories
+type FakeHTTPClientWithRepositories struct{}
+
+func (f *FakeHTTPClientWithRepositories) FetchData(ctx context.Context, language string) (*types.GitHubJSONResponse, error) {
+	return &types.GitHubJSONResponse{Items: []types.GitHubRepository{
+		{FullName: "golang/go", Owner: types.Owner{Login: "golang"}},
+		{FullName: "avelino/awesome-go", Owner: types.Owner{Login: "avelino"}},
+	}}, nil
+}
+
+// BlocklistWithNameRule simulates the blocklist with one name rule
+type BlocklistWithNameRule struct{}
+
+func (b *BlocklistWithNameRule) ReadBlocklistRules() ([]types.BlocklistRule, error) {
+	return []types.BlocklistRule{{Kind: types.NameRule, Pattern: "avelino/awesome-go"}}, nil
+}
+
+// StorageWhichRecordsRepositories records the persisted repositories
+type StorageWhichRecordsRepositories struct {
+	persisted []types.GitHubRepository
+}
+
+func (s *StorageWhichRecordsRepositories) PersistProgrammingLanguageRepositories(ctx context.Context, gh *types.GitHubJSONResponse) (types.LanguageID, error) {
+	s.persisted = gh.Items
+
+	return types.LanguageID{UUID: uuid.MustParse(languageID)}, nil
+}
+
 func Test_WithClientError(t *testing.T) {
 	pl := &types.ProgrammingLanguage{Name: "test"}
 	client := &FakeHTTPClientWithError{}
 	storage := &StorageWhichReturnsLanguageID{}
-	commandHandler := NewWriteLanguageRepositoriesCommandHandler(client, storage)
+	commandHandler := NewWriteLanguageRepositoriesCommandHandler(client, storage, &EmptyBlocklist{})
 
-	_, err := commandHandler.HandleRepositories(pl)
+	_, err := commandHandler.HandleRepositories(context.Background(), pl)
 
 	if !errors.Is(err, ErrorWhileFetchingData) {
 		t.Errorf("got result %d but expected %d", err, ErrorWhileFetchingData)
@@ -67,9 +110,9 @@ func Test_WithStorageError(t *testing.T) {
 	pl := &types.ProgrammingLanguage{Name: "test"}
 	client := &FakeHTTPClientWithoutError{}
 	storage := &StorageWhichReturnsError{}
-	commandHandler := NewWriteLanguageRepositoriesCommandHandler(client, storage)
+	commandHandler := NewWriteLanguageRepositoriesCommandHandler(client, storage, &EmptyBlocklist{})
 
-	_, err := commandHandler.HandleRepositories(pl)
+	_, err := commandHandler.HandleRepositories(context.Background(), pl)
 
 	if !errors.Is(err, ErrStorage) {
 		t.Errorf("got result %d but expected %d", err, ErrorWhileFetchingData)
@@ -84,11 +127,45 @@ func Test_WithoutError(t *testing.T) {
 	pl := &types.ProgrammingLanguage{Name: "test"}
 	client := &FakeHTTPClientWithoutError{}
 	storage := &StorageWhichReturnsLanguageID{}
-	commandHandler := NewWriteLanguageRepositoriesCommandHandler(client, storage)
+	commandHandler := NewWriteLanguageRepositoriesCommandHandler(client, storage, &EmptyBlocklist{})
 
-	result, _ := commandHandler.HandleRepositories(pl)
+	result, _ := commandHandler.HandleRepositories(context.Background(), pl)
 
 	if result.UUID.String() != languageID {
-		t.Errorf("got result %s but expected %s", result, languageID)
+		t.Errorf("got result %s but expected %s", result.UUID, languageID)
+	}
+}
+
+func Test_WithBlocklistError(t *testing.T) {
+	pl := &types.ProgrammingLanguage{Name: "test"}
+	client := &FakeHTTPClientWithoutError{}
+	storage := &StorageWhichReturnsLanguageID{}
+	commandHandler := NewWriteLanguageRepositoriesCommandHandler(client, storage, &BlocklistWhichReturnsError{})
+
+	_, err := commandHandler.HandleRepositories(context.Background(), pl)
+
+	if !errors.Is(err, ErrStorage) {
+		t.Errorf("got result %d but expected %d", err, ErrStorage)
+	}
+}
+
+func Test_WithBlocklistedRepository(t *testing.T) {
+	pl := &types.ProgrammingLanguage{Name: "go"}
+	client := &FakeHTTPClientWithRepositories{}
+	storage := &StorageWhichRecordsRepositories{}
+	commandHandler := NewWriteLanguageRepositoriesCommandHandler(client, storage, &BlocklistWithNameRule{})
+
+	result, _ := commandHandler.HandleRepositories(context.Background(), pl)
+
+	if result.Pages != 1 || result.FetchedRepositories != 2 {
+		t.Errorf("got %d pages and %d fetched repositories but expected 1 and 2", result.Pages, result.FetchedRepositories)
+	}
+
+	if result.FilteredRepositories != 1 || result.PersistedRepositories != 1 {
+		t.Errorf("got %d filtered and %d persisted but expected 1 and 1", result.FilteredRepositories, result.PersistedRepositories)
+	}
+
+	if len(storage.persisted) != 1 || storage.persisted[0].FullName != "golang/go" {
+		t.Errorf("got persisted repositories %v but expected only %s", storage.persisted, "golang/go")
 	}
 }
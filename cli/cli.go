@@ -0,0 +1,127 @@
+// Package cli implements the commands of the repositories-api binary, every command shares the configuration
+// of the server and returns the exit code of the process
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/pavbis/repositories-api/config"
+	"github.com/pavbis/repositories-api/db/pool"
+)
+
+// exit codes of the commands
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// Env represents the environment a command runs in
+type Env struct {
+	LookupEnv func(string) (string, bool)
+	Stdout    io.Writer
+	Stderr    io.Writer
+}
+
+type command struct {
+	usage string
+	run   func(env Env, args []string) int
+}
+
+func commands() map[string]command {
+	return map[string]command{
+		"serve":   {"serve [flags]", runServe},
+		"import":  {"import [flags] --language NAME [--dry-run]", runImport},
+		"export":  {"export [flags] --format csv|jsonl [--language NAME] [--output FILE]", runExport},
+		"migrate": {migrateUsage, runMigrate},
+		"prune":   {"prune [flags] [--older-than DURATION] [--audit-older-than DURATION] [--dry-run]", runPrune},
+	}
+}
+
+// Run executes the command named by the first argument, serve runs when the arguments start with a flag
+func Run(args []string, env Env) int {
+	name := "serve"
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	cmd, ok := commands()[name]
+
+	if !ok {
+		printUsage(env.Stderr)
+		return exitUsage
+	}
+
+	return cmd.run(env, args)
+}
+
+func printUsage(w io.Writer) {
+	_, _ = fmt.Fprintln(w, "usage: repositories-api <command> [flags], run a command with -h to list its flags")
+
+	for _, name := range []string{"serve", "import", "export", "migrate", "prune"} {
+		_, _ = fmt.Fprintf(w, "  repositories-api %s\n", commands()[name].usage)
+	}
+}
+
+// newFlagSet creates the flag set of the command, the config flags are added by loadConfig
+func newFlagSet(name string, env Env) *flag.FlagSet {
+	fs := flag.NewFlagSet("repositories-api "+name, flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+
+	return fs
+}
+
+// loadConfig parses the flags of the command together with the config flags
+func loadConfig(fs *flag.FlagSet, args []string, env Env) (*config.Config, []string, error) {
+	return config.LoadFlags(fs, args, env.LookupEnv)
+}
+
+// configError reports invalid flags or configuration and returns the usage exit code, -h exits successfully
+func configError(env Env, err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+
+	_, _ = fmt.Fprintln(env.Stderr, err)
+
+	return exitUsage
+}
+
+// fail reports the error of the command and returns the failure exit code
+func fail(env Env, err error) int {
+	_, _ = fmt.Fprintln(env.Stderr, err)
+	return exitFailure
+}
+
+// usageError reports invalid arguments of the command and returns the usage exit code
+func usageError(env Env, format string, a ...interface{}) int {
+	_, _ = fmt.Fprintf(env.Stderr, format+"\n", a...)
+	return exitUsage
+}
+
+// openDB opens the connection pool with the settings of the write or the read pool
+func openDB(cfg *config.Config, poolConfig config.PoolConfig) (*sql.DB, error) {
+	db, err := pool.Open(cfg.Database.URL, poolConfig)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func newLogger(cfg *config.Config, env Env) *slog.Logger {
+	return cfg.Log.NewLogger(env.Stderr)
+}
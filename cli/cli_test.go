@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+func testEnv(stdout, stderr *bytes.Buffer) Env {
+	return Env{
+		LookupEnv: func(key string) (string, bool) {
+			if key == "DATABASE_URL" {
+				return "host=127.0.0.1 port=1 sslmode=disable connect_timeout=1", true
+			}
+
+			return "", false
+		},
+		Stdout: stdout,
+		Stderr: stderr,
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		expectedCode int
+		expectedErr  string
+	}{
+		{name: "Test unknown command", args: []string{"deploy"}, expectedCode: exitUsage, expectedErr: "usage: repositories-api <command>"},
+		{name: "Test help", args: []string{"import", "-h"}, expectedCode: exitOK, expectedErr: "-dry-run"},
+		{name: "Test unknown flag", args: []string{"export", "-verbose"}, expectedCode: exitUsage, expectedErr: "flag provided but not defined"},
+		{name: "Test serve with arguments", args: []string{"serve", "now"}, expectedCode: exitUsage, expectedErr: "unexpected arguments"},
+		{name: "Test flags without command", args: []string{"-addr", ":8000", "now"}, expectedCode: exitUsage, expectedErr: "unexpected arguments"},
+		{name: "Test import without language", args: []string{"import"}, expectedCode: exitUsage, expectedErr: `unsupported language ""`},
+		{name: "Test import unsupported language", args: []string{"import", "--language", "cobol"}, expectedCode: exitUsage, expectedErr: `unsupported language "cobol"`},
+		{name: "Test export unsupported language", args: []string{"export", "--language", "cobol"}, expectedCode: exitUsage, expectedErr: `unsupported language "cobol"`},
+		{name: "Test export unsupported format", args: []string{"export", "--format", "xml"}, expectedCode: exitUsage, expectedErr: `unsupported format "xml"`},
+		{name: "Test migrate without direction", args: []string{"migrate"}, expectedCode: exitUsage, expectedErr: "usage: repositories-api migrate"},
+		{name: "Test migrate to invalid version", args: []string{"migrate", "to", "latest"}, expectedCode: exitUsage, expectedErr: `invalid version "latest"`},
+		{name: "Test prune negative retention", args: []string{"prune", "--older-than", "-1h"}, expectedCode: exitUsage, expectedErr: "the retentions must be positive"},
+		{name: "Test unreachable database", args: []string{"migrate", "status"}, expectedCode: exitFailure, expectedErr: "connection refused"},
+	}
+
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		code := Run(test.args, testEnv(&stdout, &stderr))
+
+		if code != test.expectedCode {
+			t.Errorf("%s: expected exit code %d. Got %d with %s", test.name, test.expectedCode, code, stderr.String())
+		}
+
+		if !strings.Contains(stderr.String(), test.expectedErr) {
+			t.Errorf("%s: expected error output to contain %q. Got %s", test.name, test.expectedErr, stderr.String())
+		}
+	}
+}
+
+type FakeExportStore struct {
+	repositories []types.ExportedRepository
+}
+
+func (f FakeExportStore) ExportRepositories(_ context.Context, language string, emit func(types.ExportedRepository) error) error {
+	for _, r := range f.repositories {
+		if language != "" && r.Language != language {
+			continue
+		}
+
+		if err := emit(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestExport(t *testing.T) {
+	createdAt := time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC)
+	store := FakeExportStore{repositories: []types.ExportedRepository{
+		{RepositoryID: "1", Language: "go", FullName: "golang/go", Owner: "golang", Stars: 120000,
+			Description: "The Go programming language, with a comma", CreatedAt: createdAt, ImportedAt: createdAt},
+		{RepositoryID: "2", Language: "php", FullName: "laravel/laravel", Owner: "laravel", Stars: 80000,
+			CreatedAt: createdAt, ImportedAt: createdAt},
+	}}
+
+	tests := []struct {
+		name     string
+		writer   exportWriter
+		language string
+		expected string
+	}{
+		{
+			name:     "Test csv export of one language",
+			writer:   &csvExportWriter{},
+			language: "go",
+			expected: "repository_id,language,full_name,owner,stars,description,created_at,imported_at\n" +
+				`1,go,golang/go,golang,120000,"The Go programming language, with a comma",2009-11-10T23:00:00Z,2009-11-10T23:00:00Z` + "\n",
+		},
+		{
+			name:     "Test jsonl export",
+			writer:   &jsonExportWriter{},
+			language: "php",
+			expected: `{"repository_id":"2","language":"php","full_name":"laravel/laravel","owner":"laravel","stars":80000,` +
+				`"description":"","created_at":"2009-11-10T23:00:00Z","imported_at":"2009-11-10T23:00:00Z"}` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+
+		if err := export(context.Background(), store, test.language, test.writer, &out); err != nil {
+			t.Fatalf("%s: expected no error. Got %v", test.name, err)
+		}
+
+		if out.String() != test.expected {
+			t.Errorf("%s: expected %q. Got %q", test.name, test.expected, out.String())
+		}
+	}
+}
+
+// failingCloser loses the buffered data on close like a full disk
+type failingCloser struct {
+	bytes.Buffer
+}
+
+func (f *failingCloser) Close() error {
+	return errors.New("no space left on device")
+}
+
+func TestExportFailsWhenOutputCanNotBeClosed(t *testing.T) {
+	store := FakeExportStore{repositories: []types.ExportedRepository{{RepositoryID: "1", Language: "go", FullName: "golang/go"}}}
+
+	if err := exportAndClose(context.Background(), store, "go", &csvExportWriter{}, &failingCloser{}); err == nil {
+		t.Error("Expected the close error to fail the export")
+	}
+
+	path := filepath.Join(t.TempDir(), "export.csv")
+
+	if err := exportToFile(context.Background(), store, "go", &csvExportWriter{}, path); err != nil {
+		t.Fatalf("Expected no error. Got %v", err)
+	}
+
+	if data, _ := os.ReadFile(path); !strings.HasPrefix(string(data), "repository_id,") {
+		t.Errorf("Expected the export in the file. Got %q", data)
+	}
+}
+
+// FailingAuditStore can not record audit entries
+type FailingAuditStore struct{}
+
+func (s FailingAuditStore) RecordAuditEntry(*types.AuditEntry) error {
+	return errors.New("connection refused")
+}
+
+func TestRecordingAuditEntriesReportsFailures(t *testing.T) {
+	if err := recordImport(FailingAuditStore{}, "go", types.ImportResult{}, nil); err == nil {
+		t.Error("Expected the failed import audit entry to be reported")
+	}
+
+	if err := recordPrune(FailingAuditStore{}, pruneResult{}); err == nil {
+		t.Error("Expected the failed prune audit entry to be reported")
+	}
+}
@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pavbis/repositories-api/application/storage"
+	"github.com/pavbis/repositories-api/application/types"
+	"github.com/pavbis/repositories-api/db/pool"
+	"github.com/pavbis/repositories-api/db/replica"
+)
+
+// exportColumns is the header of the csv export
+var exportColumns = []string{"repository_id", "language", "full_name", "owner", "stars", "description", "created_at", "imported_at"}
+
+// runExport writes the stored repositories which are not deleted to stdout or the output file
+func runExport(env Env, args []string) int {
+	fs := newFlagSet("export", env)
+	format := fs.String("format", "csv", "csv or jsonl, one json object per line")
+	language := fs.String("language", "", "export only the repositories of the language")
+	output := fs.String("output", "", "file to write the export to instead of stdout")
+
+	cfg, rest, err := loadConfig(fs, args, env)
+
+	if err != nil {
+		return configError(env, err)
+	}
+
+	if len(rest) > 0 {
+		return usageError(env, "unexpected arguments %v, usage: repositories-api %s", rest, commands()["export"].usage)
+	}
+
+	// an empty language exports all of them
+	if *language != "" && !types.SupportedProgrammingLanguageEnum(*language).IsValid() {
+		return usageError(env, "unsupported language %q", *language)
+	}
+
+	var w exportWriter
+
+	switch *format {
+	case "csv":
+		w = &csvExportWriter{}
+	case "jsonl":
+		w = &jsonExportWriter{}
+	default:
+		return usageError(env, "unsupported format %q, use csv or jsonl", *format)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := openDB(cfg, cfg.Database.Read)
+	if err != nil {
+		return fail(env, err)
+	}
+
+	defer func() { _ = db.Close() }()
+
+	// like the read only requests the export reads from the replica and falls back to the primary
+	var reads storage.Executor = db
+
+	if cfg.Database.ReplicaURL != "" {
+		replicaDB, err := pool.Open(cfg.Database.ReplicaURL, cfg.Database.Read)
+		if err != nil {
+			return fail(env, err)
+		}
+
+		defer func() { _ = replicaDB.Close() }()
+
+		reads = replica.NewRouter(db, replicaDB, false, newLogger(cfg, env))
+	}
+
+	store := storage.NewPostgresExportStore(reads)
+
+	if *output == "" {
+		err = export(ctx, store, *language, w, env.Stdout)
+	} else {
+		err = exportToFile(ctx, store, *language, w, *output)
+	}
+
+	if err != nil {
+		return fail(env, err)
+	}
+
+	return exitOK
+}
+
+// exportToFile writes the export to the file, the file is closed before the export counts as written
+// so a write failing on close, like a full disk, fails the export
+func exportToFile(ctx context.Context, store storage.ExportsRepositories, language string, w exportWriter, path string) error {
+	file, err := os.Create(path)
+
+	if err != nil {
+		return err
+	}
+
+	return exportAndClose(ctx, store, language, w, file)
+}
+
+func exportAndClose(ctx context.Context, store storage.ExportsRepositories, language string, w exportWriter, out io.WriteCloser) error {
+	err := export(ctx, store, language, w, out)
+
+	return errors.Join(err, out.Close())
+}
+
+func export(ctx context.Context, store storage.ExportsRepositories, language string, w exportWriter, out io.Writer) error {
+	if err := w.begin(out); err != nil {
+		return err
+	}
+
+	err := store.ExportRepositories(ctx, language, w.write)
+
+	return errors.Join(err, w.end())
+}
+
+// exportWriter writes the repositories in one format
+type exportWriter interface {
+	begin(out io.Writer) error
+	write(r types.ExportedRepository) error
+	end() error
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (e *csvExportWriter) begin(out io.Writer) error {
+	e.w = csv.NewWriter(out)
+
+	return e.w.Write(exportColumns)
+}
+
+func (e *csvExportWriter) write(r types.ExportedRepository) error {
+	return e.w.Write([]string{
+		r.RepositoryID,
+		r.Language,
+		r.FullName,
+		r.Owner,
+		strconv.FormatInt(r.Stars, 10),
+		r.Description,
+		r.CreatedAt.UTC().Format(time.RFC3339),
+		r.ImportedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+func (e *csvExportWriter) end() error {
+	e.w.Flush()
+
+	return e.w.Error()
+}
+
+type jsonExportWriter struct {
+	encoder *json.Encoder
+}
+
+func (e *jsonExportWriter) begin(out io.Writer) error {
+	e.encoder = json.NewEncoder(out)
+
+	return nil
+}
+
+func (e *jsonExportWriter) write(r types.ExportedRepository) error {
+	return e.encoder.Encode(r)
+}
+
+func (e *jsonExportWriter) end() error {
+	return nil
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pavbis/repositories-api/application/client"
+	"github.com/pavbis/repositories-api/application/storage"
+	"github.com/pavbis/repositories-api/application/types"
+	"github.com/pavbis/repositories-api/application/writemodel"
+)
+
+// cliActor is the actor of the audit entries recorded by the commands
+const cliActor = "cli"
+
+// runImport imports the repositories of one language like the POST /api/languages/{languageName} route,
+// the dry run imports within a transaction which is rolled back so nothing is persisted
+func runImport(env Env, args []string) int {
+	fs := newFlagSet("import", env)
+	language := fs.String("language", "", "programming language to import")
+	dryRun := fs.Bool("dry-run", false, "report what would be imported without persisting it")
+
+	cfg, rest, err := loadConfig(fs, args, env)
+
+	if err != nil {
+		return configError(env, err)
+	}
+
+	if len(rest) > 0 {
+		return usageError(env, "unexpected arguments %v, usage: repositories-api %s", rest, commands()["import"].usage)
+	}
+
+	if !types.SupportedProgrammingLanguageEnum(*language).IsValid() {
+		return usageError(env, "unsupported language %q", *language)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := openDB(cfg, cfg.Database.Write)
+	if err != nil {
+		return fail(env, err)
+	}
+
+	defer func() { _ = db.Close() }()
+
+	httpClient := client.NewRealHTTPClient(cfg.GitHub.BaseURL, cfg.GitHub.Timeout, nil)
+	start := time.Now()
+
+	var result types.ImportResult
+	var auditErr error
+
+	if *dryRun {
+		result, err = importInRolledBackTransaction(ctx, db, httpClient, *language)
+	} else {
+		result, err = importLanguage(ctx, db, httpClient, *language)
+		auditErr = recordImport(storage.NewPostgresAuditStore(db), *language, result, err)
+	}
+
+	logger := newLogger(cfg, env)
+
+	if auditErr != nil {
+		logger.Error("recording audit entry failed", "action", types.LanguageImportAction, "actor", cliActor, "error", auditErr.Error())
+	}
+
+	if err != nil {
+		logger.Error("import failed", "language", *language, "error", err.Error())
+		return exitFailure
+	}
+
+	prefix := ""
+	if *dryRun {
+		prefix = "dry run: "
+	}
+
+	_, _ = fmt.Fprintf(env.Stdout, "%s%s fetched %d, persisted %d, filtered by blocklist %d in %s\n",
+		prefix, *language, result.FetchedRepositories, result.PersistedRepositories, result.FilteredRepositories,
+		time.Since(start).Round(time.Millisecond))
+
+	// without the audit entry the readiness does not see the import, so the run counts as failed
+	if auditErr != nil {
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+func importLanguage(ctx context.Context, db storage.Executor, httpClient client.HTTPClient, language string) (types.ImportResult, error) {
+	commandHandler := writemodel.NewWriteLanguageRepositoriesCommandHandler(
+		httpClient, storage.NewPostgresWriteStore(db), storage.NewPostgresBlocklistStore(db))
+
+	return commandHandler.HandleRepositories(ctx, &types.ProgrammingLanguage{Name: language})
+}
+
+func importInRolledBackTransaction(ctx context.Context, db *sql.DB, httpClient client.HTTPClient, language string) (types.ImportResult, error) {
+	tx, err := db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return types.ImportResult{}, err
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	return importLanguage(ctx, tx, httpClient, language)
+}
+
+// recordImport records the import in the audit log like the api does, the readiness reports the last success from it
+func recordImport(store storage.RecordsAuditEntries, language string, result types.ImportResult, err error) error {
+	entry := &types.AuditEntry{
+		Actor:   cliActor,
+		Action:  types.LanguageImportAction,
+		Targets: []string{language},
+		Outcome: types.AuditFailure,
+	}
+
+	if err == nil {
+		entry.Targets = append(entry.Targets, result.UUID.String())
+		entry.Outcome = types.AuditSuccess
+	}
+
+	return store.RecordAuditEntry(entry)
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/pavbis/repositories-api/db/migrations"
+)
+
+const migrateUsage = "migrate [flags] up|down|status|to N|force N"
+
+// runMigrate applies or rolls back the embedded migrations, down rolls back the latest migration only
+func runMigrate(env Env, args []string) int {
+	cfg, rest, err := loadConfig(newFlagSet("migrate", env), args, env)
+
+	if err != nil {
+		return configError(env, err)
+	}
+
+	if len(rest) == 0 {
+		return usageError(env, "usage: repositories-api %s", migrateUsage)
+	}
+
+	var version uint
+
+	switch rest[0] {
+	case "up", "down", "status":
+		if len(rest) != 1 {
+			return usageError(env, "usage: repositories-api %s", migrateUsage)
+		}
+	case "to", "force":
+		if len(rest) != 2 {
+			return usageError(env, "usage: repositories-api %s", migrateUsage)
+		}
+
+		v, err := strconv.ParseUint(rest[1], 10, 64)
+		if err != nil {
+			return usageError(env, "invalid version %q", rest[1])
+		}
+
+		version = uint(v)
+	default:
+		return usageError(env, "usage: repositories-api %s", migrateUsage)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := openDB(cfg, cfg.Database.Write)
+	if err != nil {
+		return fail(env, err)
+	}
+
+	defer func() { _ = db.Close() }()
+
+	migrator := migrations.NewMigrator(db, newLogger(cfg, env))
+
+	switch rest[0] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx)
+	case "to":
+		err = migrator.To(ctx, version)
+	case "force":
+		err = migrator.Force(ctx, version)
+	case "status":
+		err = printMigrationStatus(ctx, env, migrator)
+	}
+
+	if err != nil {
+		return fail(env, err)
+	}
+
+	return exitOK
+}
+
+func printMigrationStatus(ctx context.Context, env Env, migrator *migrations.Migrator) error {
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(env.Stdout, "version %d, dirty %t\n", status.Version, status.Dirty)
+
+	for _, m := range status.Applied {
+		_, _ = fmt.Fprintf(env.Stdout, "applied  %06d_%s\n", m.Version, m.Name)
+	}
+
+	for _, m := range status.Pending {
+		_, _ = fmt.Fprintf(env.Stdout, "pending  %06d_%s\n", m.Version, m.Name)
+	}
+
+	return nil
+}
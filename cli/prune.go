@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pavbis/repositories-api/application/storage"
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+// idleBucketTTL matches the in memory store, idle buckets are full again and can be dropped
+const idleBucketTTL = 24 * time.Hour
+
+// pruneResult represents the number of the removed rows per kind of data
+type pruneResult struct {
+	apiKeys      int64
+	buckets      int64
+	auditEntries int64
+}
+
+// runPrune removes the api keys revoked or expired before the retention, the idle rate limit buckets
+// and optionally the old audit entries. The deleted repositories are kept so the import does not restore them.
+// All of it runs in one transaction, the dry run rolls it back and only reports the numbers.
+func runPrune(env Env, args []string) int {
+	fs := newFlagSet("prune", env)
+	olderThan := fs.Duration("older-than", 30*24*time.Hour, "retention of the revoked or expired api keys")
+	auditOlderThan := fs.Duration("audit-older-than", 0, "retention of the audit log, 0 keeps all entries")
+	dryRun := fs.Bool("dry-run", false, "report what would be removed without removing it")
+
+	cfg, rest, err := loadConfig(fs, args, env)
+
+	if err != nil {
+		return configError(env, err)
+	}
+
+	if len(rest) > 0 {
+		return usageError(env, "unexpected arguments %v, usage: repositories-api %s", rest, commands()["prune"].usage)
+	}
+
+	if *olderThan <= 0 || *auditOlderThan < 0 {
+		return usageError(env, "the retentions must be positive")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := openDB(cfg, cfg.Database.Write)
+	if err != nil {
+		return fail(env, err)
+	}
+
+	defer func() { _ = db.Close() }()
+
+	now := time.Now()
+	result, err := pruneInTransaction(ctx, db, !*dryRun, func(store storage.PrunesData) (r pruneResult, err error) {
+		if r.apiKeys, err = store.PruneAPIKeys(ctx, now.Add(-*olderThan)); err != nil {
+			return r, err
+		}
+
+		if r.buckets, err = store.PruneRateLimitBuckets(ctx, now.Add(-idleBucketTTL)); err != nil {
+			return r, err
+		}
+
+		if *auditOlderThan > 0 {
+			r.auditEntries, err = store.PruneAuditLog(ctx, now.Add(-*auditOlderThan))
+		}
+
+		return r, err
+	})
+
+	if err != nil {
+		return fail(env, err)
+	}
+
+	prefix := ""
+	if *dryRun {
+		prefix = "dry run: "
+	}
+
+	_, _ = fmt.Fprintf(env.Stdout, "%spruned %d api keys, %d rate limit buckets, %d audit entries\n",
+		prefix, result.apiKeys, result.buckets, result.auditEntries)
+
+	if *dryRun {
+		return exitOK
+	}
+
+	if err = recordPrune(storage.NewPostgresAuditStore(db), result); err != nil {
+		newLogger(cfg, env).Error("recording audit entry failed", "action", types.DataPruneAction, "actor", cliActor, "error", err.Error())
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+func pruneInTransaction(ctx context.Context, db *sql.DB, commit bool, prune func(storage.PrunesData) (pruneResult, error)) (pruneResult, error) {
+	tx, err := db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return pruneResult{}, err
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := prune(storage.NewPostgresMaintenanceStore(tx))
+
+	if err != nil || !commit {
+		return result, err
+	}
+
+	return result, tx.Commit()
+}
+
+func recordPrune(store storage.RecordsAuditEntries, result pruneResult) error {
+	return store.RecordAuditEntry(&types.AuditEntry{
+		Actor:  cliActor,
+		Action: types.DataPruneAction,
+		Targets: []string{
+			"api_keys=" + strconv.FormatInt(result.apiKeys, 10),
+			"rate_limit_buckets=" + strconv.FormatInt(result.buckets, 10),
+			"audit_entries=" + strconv.FormatInt(result.auditEntries, 10),
+		},
+		Outcome: types.AuditSuccess,
+	})
+}
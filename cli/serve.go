@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"github.com/pavbis/repositories-api/api"
+)
+
+// runServe starts the api and serves until SIGINT or SIGTERM
+func runServe(env Env, args []string) int {
+	cfg, rest, err := loadConfig(newFlagSet("serve", env), args, env)
+
+	if err != nil {
+		return configError(env, err)
+	}
+
+	if len(rest) > 0 {
+		return usageError(env, "unexpected arguments %v, usage: repositories-api %s", rest, commands()["serve"].usage)
+	}
+
+	s := api.Server{}
+	s.Initialize(cfg)
+
+	if err = s.Run(); err != nil {
+		return fail(env, err)
+	}
+
+	return exitOK
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"flag"
+	"strconv"
+	"time"
+)
+
+// binding connects a config value with its environment variable and command line flag
+type binding struct {
+	env   string
+	flag  string
+	usage string
+	value flag.Value
+}
+
+// bindings lists the values which can be set by environment variables and flags,
+// the variable names of the earlier releases are kept
+func (c *Config) bindings() []binding {
+	return []binding{
+		{"HTTP_ADDR", "addr", "listen address of the http server", (*stringValue)(&c.Server.Addr)},
+		{"HTTP_READ_TIMEOUT", "read-timeout", "timeout for reading the whole request", (*durationValue)(&c.Server.ReadTimeout)},
+		{"HTTP_READ_HEADER_TIMEOUT", "read-header-timeout", "timeout for reading the request headers", (*durationValue)(&c.Server.ReadHeaderTimeout)},
+		{"HTTP_WRITE_TIMEOUT", "write-timeout", "timeout for writing the response", (*durationValue)(&c.Server.WriteTimeout)},
+		{"HTTP_IDLE_TIMEOUT", "idle-timeout", "timeout of idle keep-alive connections", (*durationValue)(&c.Server.IdleTimeout)},
+		{"HTTP_SHUTDOWN_TIMEOUT", "shutdown-timeout", "time to drain requests and imports on shutdown", (*durationValue)(&c.Server.ShutdownTimeout)},
+		{"DATABASE_URL", "database-url", "postgres connection string", (*stringValue)(&c.Database.URL)},
+		{"DATABASE_AUTO_MIGRATE", "database-auto-migrate", "apply the pending migrations on start", (*boolValue)(&c.Database.AutoMigrate)},
+		{"DATABASE_MAX_OPEN_CONNS", "database-max-open-conns", "open connections of the write pool", (*intValue)(&c.Database.Write.MaxOpenConns)},
+		{"DATABASE_MAX_IDLE_CONNS", "database-max-idle-conns", "idle connections of the write pool", (*intValue)(&c.Database.Write.MaxIdleConns)},
+		{"DATABASE_CONN_MAX_LIFETIME", "database-conn-max-lifetime", "lifetime of the write pool connections", (*durationValue)(&c.Database.Write.ConnMaxLifetime)},
+		{"DATABASE_CONN_MAX_IDLE_TIME", "database-conn-max-idle-time", "idle time of the write pool connections", (*durationValue)(&c.Database.Write.ConnMaxIdleTime)},
+		{"DATABASE_STATEMENT_TIMEOUT", "database-statement-timeout", "statement timeout of the write pool", (*durationValue)(&c.Database.Write.StatementTimeout)},
+		{"DATABASE_READ_MAX_OPEN_CONNS", "database-read-max-open-conns", "open connections of the read pool", (*intValue)(&c.Database.Read.MaxOpenConns)},
+		{"DATABASE_READ_MAX_IDLE_CONNS", "database-read-max-idle-conns", "idle connections of the read pool", (*intValue)(&c.Database.Read.MaxIdleConns)},
+		{"DATABASE_READ_CONN_MAX_LIFETIME", "database-read-conn-max-lifetime", "lifetime of the read pool connections", (*durationValue)(&c.Database.Read.ConnMaxLifetime)},
+		{"DATABASE_READ_CONN_MAX_IDLE_TIME", "database-read-conn-max-idle-time", "idle time of the read pool connections", (*durationValue)(&c.Database.Read.ConnMaxIdleTime)},
+		{"DATABASE_READ_STATEMENT_TIMEOUT", "database-read-statement-timeout", "statement timeout of the read pool", (*durationValue)(&c.Database.Read.StatementTimeout)},
+		{"DATABASE_REPLICA_URL", "database-replica-url", "postgres connection string of the read replica", (*stringValue)(&c.Database.ReplicaURL)},
+		{"DATABASE_READ_YOUR_WRITES", "database-read-your-writes", "read from the primary until the replica replayed the latest import", (*boolValue)(&c.Database.ReadYourWrites)},
+		{"AUTH_USER", "auth-user", "basic auth user of the admin", (*stringValue)(&c.Auth.User)},
+		{"AUTH_PASS", "auth-pass", "basic auth password of the admin", (*stringValue)(&c.Auth.Pass)},
+		{"AUTH_USERS", "auth-users", "comma separated user:password:role entries", (*stringValue)(&c.Auth.Users)},
+		{"AUTH_HTPASSWD_FILE", "auth-htpasswd-file", "htpasswd file with bcrypt hashed users", (*stringValue)(&c.Auth.HtpasswdFile)},
+		{"JWT_JWKS", "jwt-jwks", "file or url of the identity provider's key set", (*stringValue)(&c.Auth.JWT.JWKS)},
+		{"JWT_JWKS_REFRESH_INTERVAL", "jwt-jwks-refresh-interval", "interval of the key set reload", (*durationValue)(&c.Auth.JWT.RefreshInterval)},
+		{"JWT_ISSUER", "jwt-issuer", "required token issuer", (*stringValue)(&c.Auth.JWT.Issuer)},
+		{"JWT_AUDIENCE", "jwt-audience", "required token audience", (*stringValue)(&c.Auth.JWT.Audience)},
+		{"JWT_ROLES_CLAIM", "jwt-roles-claim", "claim holding the roles", (*stringValue)(&c.Auth.JWT.RolesClaim)},
+		{"JWT_ROLE_MAPPING", "jwt-role-mapping", "comma separated claim-value:role entries", (*stringValue)(&c.Auth.JWT.RoleMapping)},
+		{"GITHUB_API_URL", "github-api-url", "base url of the GitHub api", (*stringValue)(&c.GitHub.BaseURL)},
+		{"GITHUB_TIMEOUT", "github-timeout", "timeout of the GitHub api requests", (*durationValue)(&c.GitHub.Timeout)},
+		{"RATE_LIMIT_STORE", "rate-limit-store", "memory or postgres", (*stringValue)(&c.RateLimit.Store)},
+		{"LOG_LEVEL", "log-level", "debug, info, warn or error", (*stringValue)(&c.Log.Level)},
+		{"LOG_FORMAT", "log-format", "json or text", (*stringValue)(&c.Log.Format)},
+		{"RATE_LIMITS", "rate-limits", "comma separated route=requests/period entries", (*stringValue)(&c.RateLimit.Limits)},
+		{"TRACING_EXPORTER", "tracing-exporter", "none, otlp or stdout", (*stringValue)(&c.Tracing.Exporter)},
+		{"TRACING_ENDPOINT", "tracing-endpoint", "host:port of the OTLP http collector", (*stringValue)(&c.Tracing.Endpoint)},
+		{"TRACING_INSECURE", "tracing-insecure", "send the spans to the collector without tls", (*boolValue)(&c.Tracing.Insecure)},
+		{"TRACING_FILE", "tracing-file", "file receiving the spans of the stdout exporter", (*stringValue)(&c.Tracing.File)},
+		{"TRACING_SERVICE_NAME", "tracing-service-name", "service name of the spans", (*stringValue)(&c.Tracing.ServiceName)},
+		{"TRACING_SAMPLE_RATIO", "tracing-sample-ratio", "ratio of the sampled traces between 0 and 1", (*floatValue)(&c.Tracing.SampleRatio)},
+	}
+}
+
+type stringValue string
+
+func (v *stringValue) Set(value string) error {
+	*v = stringValue(value)
+	return nil
+}
+
+func (v *stringValue) String() string {
+	return string(*v)
+}
+
+type durationValue time.Duration
+
+func (v *durationValue) Set(value string) error {
+	d, err := time.ParseDuration(value)
+
+	if err != nil {
+		return err
+	}
+
+	*v = durationValue(d)
+
+	return nil
+}
+
+func (v *durationValue) String() string {
+	return time.Duration(*v).String()
+}
+
+type boolValue bool
+
+func (v *boolValue) Set(value string) error {
+	b, err := strconv.ParseBool(value)
+
+	if err != nil {
+		return err
+	}
+
+	*v = boolValue(b)
+
+	return nil
+}
+
+func (v *boolValue) String() string {
+	return strconv.FormatBool(bool(*v))
+}
+
+type floatValue float64
+
+func (v *floatValue) Set(value string) error {
+	f, err := strconv.ParseFloat(value, 64)
+
+	if err != nil {
+		return err
+	}
+
+	*v = floatValue(f)
+
+	return nil
+}
+
+func (v *floatValue) String() string {
+	return strconv.FormatFloat(float64(*v), 'g', -1, 64)
+}
+
+type intValue int
+
+func (v *intValue) Set(value string) error {
+	i, err := strconv.Atoi(value)
+
+	if err != nil {
+		return err
+	}
+
+	*v = intValue(i)
+
+	return nil
+}
+
+func (v *intValue) String() string {
+	return strconv.Itoa(int(*v))
+}
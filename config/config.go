@@ -0,0 +1,354 @@
+// Package config loads the typed configuration of the api. Every value has a default which can be overridden
+// by the config file, the environment and the command line flags, later sources win.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config represents the whole configuration of the api
+type Config struct {
+	Server    ServerConfig    `yaml:"server" toml:"server"`
+	Database  DatabaseConfig  `yaml:"database" toml:"database"`
+	Auth      AuthConfig      `yaml:"auth" toml:"auth"`
+	GitHub    GitHubConfig    `yaml:"github" toml:"github"`
+	RateLimit RateLimitConfig `yaml:"rate_limit" toml:"rate_limit"`
+	Log       LogConfig       `yaml:"log" toml:"log"`
+	Tracing   TracingConfig   `yaml:"tracing" toml:"tracing"`
+}
+
+// ServerConfig represents the listen address and the timeouts of the http server
+type ServerConfig struct {
+	Addr              string        `yaml:"addr" toml:"addr"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	// ShutdownTimeout limits how long the in-flight requests and imports are awaited on shutdown
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+}
+
+// DatabaseConfig represents the postgres connection
+type DatabaseConfig struct {
+	URL string `yaml:"url" toml:"url"`
+	// AutoMigrate applies the pending migrations on start, instances starting together migrate one after another
+	AutoMigrate bool `yaml:"auto_migrate" toml:"auto_migrate"`
+	// Write is the pool of the mutating requests, the imports and the authentication
+	Write PoolConfig `yaml:"write" toml:"write"`
+	// Read is the pool of the read only requests, so heavy reads can not exhaust the connections of the writes
+	Read PoolConfig `yaml:"read" toml:"read"`
+	// ReplicaURL is the optional read replica, the read pool connects to it and falls back to the primary when it fails
+	ReplicaURL string `yaml:"replica_url" toml:"replica_url"`
+	// ReadYourWrites reads from the primary after an import until the replica replayed it
+	ReadYourWrites bool `yaml:"read_your_writes" toml:"read_your_writes"`
+}
+
+// PoolConfig represents the connection pool settings, zero connections are unlimited
+type PoolConfig struct {
+	MaxOpenConns    int           `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time"`
+	// StatementTimeout lets postgres cancel every statement running longer, zero disables it
+	StatementTimeout time.Duration `yaml:"statement_timeout" toml:"statement_timeout"`
+}
+
+// AuthConfig represents the sources of the clients which may use the api
+type AuthConfig struct {
+	// User and Pass are the basic auth credentials of the admin
+	User string `yaml:"user" toml:"user"`
+	Pass string `yaml:"pass" toml:"pass"`
+	// Users is the comma separated list of user:password:role entries
+	Users        string    `yaml:"users" toml:"users"`
+	HtpasswdFile string    `yaml:"htpasswd_file" toml:"htpasswd_file"`
+	JWT          JWTConfig `yaml:"jwt" toml:"jwt"`
+}
+
+// JWTConfig represents the identity provider whose bearer tokens are accepted, disabled without JWKS.
+// The issuer and the audience are required with JWKS so tokens the provider issued for other clients are rejected
+type JWTConfig struct {
+	JWKS            string        `yaml:"jwks" toml:"jwks"`
+	RefreshInterval time.Duration `yaml:"refresh_interval" toml:"refresh_interval"`
+	Issuer          string        `yaml:"issuer" toml:"issuer"`
+	Audience        string        `yaml:"audience" toml:"audience"`
+	RolesClaim      string        `yaml:"roles_claim" toml:"roles_claim"`
+	// RoleMapping is the comma separated list of claim-value:role entries
+	RoleMapping string `yaml:"role_mapping" toml:"role_mapping"`
+}
+
+// GitHubConfig represents the GitHub api the repositories are imported from
+type GitHubConfig struct {
+	BaseURL string        `yaml:"base_url" toml:"base_url"`
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+}
+
+// RateLimitConfig represents where the rate limit buckets are kept and the limits overriding the defaults
+type RateLimitConfig struct {
+	Store string `yaml:"store" toml:"store"`
+	// Limits is the comma separated list of route=requests/period entries
+	Limits string `yaml:"limits" toml:"limits"`
+}
+
+// LogConfig represents the minimum level and the format of the logs
+type LogConfig struct {
+	Level  string `yaml:"level" toml:"level"`
+	Format string `yaml:"format" toml:"format"`
+}
+
+// TracingConfig represents where the spans are exported to, tracing is disabled with the none exporter
+type TracingConfig struct {
+	// Exporter is none, otlp or stdout
+	Exporter string `yaml:"exporter" toml:"exporter"`
+	// Endpoint is the host:port of the OTLP http collector, empty uses the OTEL_EXPORTER_OTLP_* variables
+	Endpoint string `yaml:"endpoint" toml:"endpoint"`
+	Insecure bool   `yaml:"insecure" toml:"insecure"`
+	// File receives the spans of the stdout exporter, empty writes to stdout
+	File        string  `yaml:"file" toml:"file"`
+	ServiceName string  `yaml:"service_name" toml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+}
+
+// NewLogger creates the structured logger writing in the configured format
+func (c LogConfig) NewLogger(w io.Writer) *slog.Logger {
+	options := &slog.HandlerOptions{Level: c.SlogLevel()}
+
+	if c.Format == "text" {
+		return slog.New(slog.NewTextHandler(w, options))
+	}
+
+	return slog.New(slog.NewJSONHandler(w, options))
+}
+
+// SlogLevel converts the configured level, unknown levels are rejected by Validate
+func (c LogConfig) SlogLevel() slog.Level {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(c.Level))
+
+	return level
+}
+
+// Default returns the configuration used for all values which are not configured
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:              ":7000",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 10 * time.Second,
+			WriteTimeout:      15 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Write: PoolConfig{
+				MaxOpenConns:     10,
+				MaxIdleConns:     5,
+				ConnMaxLifetime:  30 * time.Minute,
+				ConnMaxIdleTime:  5 * time.Minute,
+				StatementTimeout: 30 * time.Second,
+			},
+			Read: PoolConfig{
+				MaxOpenConns:     20,
+				MaxIdleConns:     10,
+				ConnMaxLifetime:  30 * time.Minute,
+				ConnMaxIdleTime:  5 * time.Minute,
+				StatementTimeout: 30 * time.Second,
+			},
+		},
+		Auth: AuthConfig{
+			JWT: JWTConfig{RefreshInterval: time.Hour, RolesClaim: "roles"},
+		},
+		GitHub: GitHubConfig{
+			BaseURL: "https://api.github.com",
+			Timeout: 5 * time.Second,
+		},
+		RateLimit: RateLimitConfig{Store: "memory"},
+		Log:       LogConfig{Level: "info", Format: "json"},
+		Tracing:   TracingConfig{Exporter: "none", ServiceName: "repositories-api", SampleRatio: 1},
+	}
+}
+
+// Load builds the configuration from the defaults, the config file, the environment and the flags.
+// The config file is named by the -config flag or the CONFIG_FILE variable, .yaml, .yml and .toml files are supported.
+// The arguments following the flags are returned.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, []string, error) {
+	return LoadFlags(flag.NewFlagSet("repositories-api", flag.ContinueOnError), args, lookupEnv)
+}
+
+// LoadFlags works like Load but parses the args with the flag set, so commands can define their own flags next to
+// the config flags
+func LoadFlags(fs *flag.FlagSet, args []string, lookupEnv func(string) (string, bool)) (*Config, []string, error) {
+	c := Default()
+	configFile := fs.String("config", "", "path of the yaml or toml config file")
+	flagValues := make(map[string]string)
+
+	for _, b := range c.bindings() {
+		name := b.flag
+		fs.Func(name, fmt.Sprintf("%s (env %s)", b.usage, b.env), func(value string) error {
+			flagValues[name] = value
+			return nil
+		})
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	path := *configFile
+
+	if path == "" {
+		path, _ = lookupEnv("CONFIG_FILE")
+	}
+
+	if path != "" {
+		if err := c.loadFile(path); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, b := range c.bindings() {
+		if value, ok := lookupEnv(b.env); ok {
+			if err := b.value.Set(value); err != nil {
+				return nil, nil, fmt.Errorf("invalid %s: %w", b.env, err)
+			}
+		}
+	}
+
+	for _, b := range c.bindings() {
+		if value, ok := flagValues[b.flag]; ok {
+			if err := b.value.Set(value); err != nil {
+				return nil, nil, fmt.Errorf("invalid -%s: %w", b.flag, err)
+			}
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	return c, fs.Args(), nil
+}
+
+func (c *Config) loadFile(path string) error {
+	var unmarshal func([]byte, interface{}) error
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		unmarshal = yaml.Unmarshal
+	case ".toml":
+		unmarshal = toml.Unmarshal
+	default:
+		return fmt.Errorf("unsupported config file %s, use .yaml, .yml or .toml", path)
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return err
+	}
+
+	if err = unmarshal(data, c); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Validate reports all invalid values at once
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Server.Addr == "" {
+		errs = append(errs, errors.New("server address is required"))
+	}
+
+	if c.Database.URL == "" {
+		errs = append(errs, errors.New("database url is required"))
+	}
+
+	timeouts := map[string]time.Duration{
+		"server read timeout":        c.Server.ReadTimeout,
+		"server read header timeout": c.Server.ReadHeaderTimeout,
+		"server write timeout":       c.Server.WriteTimeout,
+		"server idle timeout":        c.Server.IdleTimeout,
+		"server shutdown timeout":    c.Server.ShutdownTimeout,
+		"github timeout":             c.GitHub.Timeout,
+		"jwt refresh interval":       c.Auth.JWT.RefreshInterval,
+	}
+
+	for name, timeout := range timeouts {
+		if timeout <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", name))
+		}
+	}
+
+	errs = append(errs, c.Database.Write.validate("write")...)
+	errs = append(errs, c.Database.Read.validate("read")...)
+
+	if c.Database.ReadYourWrites && c.Database.ReplicaURL == "" {
+		errs = append(errs, errors.New("database read your writes requires a replica url"))
+	}
+
+	if (c.Auth.User == "") != (c.Auth.Pass == "") {
+		errs = append(errs, errors.New("auth user and pass must be configured together"))
+	}
+
+	if !strings.HasPrefix(c.GitHub.BaseURL, "http://") && !strings.HasPrefix(c.GitHub.BaseURL, "https://") {
+		errs = append(errs, fmt.Errorf("invalid github base url %q", c.GitHub.BaseURL))
+	}
+
+	if c.Auth.JWT.JWKS != "" && (c.Auth.JWT.Issuer == "" || c.Auth.JWT.Audience == "") {
+		errs = append(errs, errors.New("jwt issuer and audience are required with jwks"))
+	}
+
+	if c.RateLimit.Store != "memory" && c.RateLimit.Store != "postgres" {
+		errs = append(errs, fmt.Errorf("unknown rate limit store %q, use memory or postgres", c.RateLimit.Store))
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		errs = append(errs, fmt.Errorf("unknown log level %q, use debug, info, warn or error", c.Log.Level))
+	}
+
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		errs = append(errs, fmt.Errorf("unknown log format %q, use json or text", c.Log.Format))
+	}
+
+	if c.Tracing.Exporter != "none" && c.Tracing.Exporter != "otlp" && c.Tracing.Exporter != "stdout" {
+		errs = append(errs, fmt.Errorf("unknown tracing exporter %q, use none, otlp or stdout", c.Tracing.Exporter))
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, errors.New("tracing sample ratio must be between 0 and 1"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p PoolConfig) validate(name string) []error {
+	var errs []error
+
+	if p.MaxOpenConns < 0 || p.MaxIdleConns < 0 {
+		errs = append(errs, fmt.Errorf("database %s pool connections must not be negative", name))
+	}
+
+	if p.MaxOpenConns > 0 && p.MaxIdleConns > p.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("database %s pool idle connections exceed the open connections", name))
+	}
+
+	if p.ConnMaxLifetime < 0 || p.ConnMaxIdleTime < 0 || p.StatementTimeout < 0 {
+		errs = append(errs, fmt.Errorf("database %s pool durations must not be negative", name))
+	}
+
+	return errs
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func envOf(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	c, _, err := Load(nil, envOf(map[string]string{"DATABASE_URL": "postgres://localhost/db"}))
+
+	if err != nil {
+		t.Fatalf("Expected no error. Got %v", err)
+	}
+
+	if c.Server.Addr != ":7000" || c.Server.WriteTimeout != 15*time.Second || c.GitHub.BaseURL != "https://api.github.com" ||
+		c.RateLimit.Store != "memory" {
+		t.Errorf("Got unexpected defaults %+v", c)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+server:
+  addr: ":8000"
+  write_timeout: 30s
+database:
+  url: postgres://file/db
+github:
+  timeout: 10s
+`)
+
+	env := envOf(map[string]string{
+		"CONFIG_FILE":    path,
+		"DATABASE_URL":   "postgres://env/db",
+		"GITHUB_TIMEOUT": "20s",
+	})
+
+	c, _, err := Load([]string{"-github-timeout", "25s"}, env)
+
+	if err != nil {
+		t.Fatalf("Expected no error. Got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		got      interface{}
+		expected interface{}
+	}{
+		{name: "file overrides default", got: c.Server.Addr, expected: ":8000"},
+		{name: "file duration", got: c.Server.WriteTimeout, expected: 30 * time.Second},
+		{name: "env overrides file", got: c.Database.URL, expected: "postgres://env/db"},
+		{name: "flag overrides env", got: c.GitHub.Timeout, expected: 25 * time.Second},
+		{name: "default is kept", got: c.Server.IdleTimeout, expected: 120 * time.Second},
+	}
+
+	for _, test := range tests {
+		if test.got != test.expected {
+			t.Errorf("for precedence test '%s', got %v but expected %v", test.name, test.got, test.expected)
+		}
+	}
+}
+
+func TestLoadReturnsArguments(t *testing.T) {
+	c, args, err := Load([]string{"-database-auto-migrate", "true", "migrate", "to", "3"}, envOf(map[string]string{"DATABASE_URL": "postgres://env/db"}))
+
+	if err != nil {
+		t.Fatalf("Expected no error. Got %v", err)
+	}
+
+	if !c.Database.AutoMigrate {
+		t.Error("Expected auto migrate to be enabled")
+	}
+
+	if strings.Join(args, " ") != "migrate to 3" {
+		t.Errorf("Expected the arguments following the flags. Got %v", args)
+	}
+}
+
+func TestLoadTOMLFileFromFlag(t *testing.T) {
+	path := writeConfigFile(t, "config.toml", `
+[database]
+url = "postgres://toml/db"
+
+[rate_limit]
+store = "postgres"
+limits = "default=100/1m"
+
+[auth.jwt]
+jwks = "https://id.example.com/jwks.json"
+refresh_interval = "10m"
+issuer = "https://id.example.com"
+audience = "repositories-api"
+`)
+
+	c, _, err := Load([]string{"-config", path}, envOf(nil))
+
+	if err != nil {
+		t.Fatalf("Expected no error. Got %v", err)
+	}
+
+	if c.Database.URL != "postgres://toml/db" || c.RateLimit.Store != "postgres" || c.RateLimit.Limits != "default=100/1m" ||
+		c.Auth.JWT.JWKS != "https://id.example.com/jwks.json" || c.Auth.JWT.RefreshInterval != 10*time.Minute ||
+		c.Auth.JWT.Audience != "repositories-api" {
+		t.Errorf("Got unexpected config %+v", c)
+	}
+}
+
+func TestLoadWithInvalidValues(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		env           map[string]string
+		expectedError string
+	}{
+		{
+			name:          "Test missing database url",
+			expectedError: "database url is required",
+		},
+		{
+			name:          "Test invalid duration",
+			env:           map[string]string{"DATABASE_URL": "postgres://env/db", "HTTP_WRITE_TIMEOUT": "soon"},
+			expectedError: "invalid HTTP_WRITE_TIMEOUT",
+		},
+		{
+			name:          "Test negative timeout",
+			args:          []string{"-database-url", "postgres://flag/db", "-github-timeout", "-1s"},
+			expectedError: "github timeout must be positive",
+		},
+		{
+			name:          "Test unknown rate limit store",
+			env:           map[string]string{"DATABASE_URL": "postgres://env/db", "RATE_LIMIT_STORE": "redis"},
+			expectedError: `unknown rate limit store "redis"`,
+		},
+		{
+			name:          "Test admin user without password",
+			env:           map[string]string{"DATABASE_URL": "postgres://env/db", "AUTH_USER": "admin"},
+			expectedError: "auth user and pass must be configured together",
+		},
+		{
+			name:          "Test unknown log level",
+			env:           map[string]string{"DATABASE_URL": "postgres://env/db", "LOG_LEVEL": "verbose"},
+			expectedError: `unknown log level "verbose"`,
+		},
+		{
+			name:          "Test more idle than open connections",
+			env:           map[string]string{"DATABASE_URL": "postgres://env/db", "DATABASE_READ_MAX_IDLE_CONNS": "50"},
+			expectedError: "database read pool idle connections exceed the open connections",
+		},
+		{
+			name:          "Test read your writes without replica",
+			env:           map[string]string{"DATABASE_URL": "postgres://env/db", "DATABASE_READ_YOUR_WRITES": "true"},
+			expectedError: "database read your writes requires a replica url",
+		},
+		{
+			name:          "Test jwks without audience",
+			env:           map[string]string{"DATABASE_URL": "postgres://env/db", "JWT_JWKS": "jwks.json", "JWT_ISSUER": "https://id.example.com"},
+			expectedError: "jwt issuer and audience are required with jwks",
+		},
+		{
+			name:          "Test invalid pool size",
+			args:          []string{"-database-url", "postgres://flag/db", "-database-max-open-conns", "many"},
+			expectedError: "invalid -database-max-open-conns",
+		},
+		{
+			name:          "Test unknown tracing exporter",
+			env:           map[string]string{"DATABASE_URL": "postgres://env/db", "TRACING_EXPORTER": "jaeger"},
+			expectedError: `unknown tracing exporter "jaeger"`,
+		},
+		{
+			name:          "Test invalid tracing sample ratio",
+			args:          []string{"-database-url", "postgres://flag/db", "-tracing-sample-ratio", "1.5"},
+			expectedError: "tracing sample ratio must be between 0 and 1",
+		},
+		{
+			name:          "Test unsupported config file",
+			env:           map[string]string{"CONFIG_FILE": "config.json"},
+			expectedError: "unsupported config file",
+		},
+		{
+			name:          "Test unknown flag",
+			args:          []string{"-port", "7000"},
+			expectedError: "flag provided but not defined",
+		},
+	}
+
+	for _, test := range tests {
+		_, _, err := Load(test.args, envOf(test.env))
+
+		if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+			t.Errorf("for config test '%s', got error %v but expected %q", test.name, err, test.expectedError)
+		}
+	}
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS repositories_owner_idx;
+DROP TABLE IF EXISTS "owners";
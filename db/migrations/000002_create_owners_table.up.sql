@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS "owners"
+(
+    "ownerId"       CHAR(36)        NOT NULL PRIMARY KEY,
+    "login"         non_empty       UNIQUE,
+    "type"          VARCHAR(20)     NOT NULL CHECK ( "type" IN ('user', 'organization') ),
+    "avatar_url"    TEXT            NOT NULL,
+    "created_at"    timestamptz     NOT NULL DEFAULT (NOW()),
+    "updated_at"    timestamptz     NOT NULL DEFAULT (NOW())
+);
+
+CREATE INDEX repositories_owner_idx ON repositories (owner);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS "topics"
+(
+    "topicId"       CHAR(36)        NOT NULL PRIMARY KEY,
+    "name"          non_empty       UNIQUE,
+    "created_at"    timestamptz     NOT NULL DEFAULT (NOW())
+);
+
+CREATE TABLE IF NOT EXISTS "repository_topics"
+(
+    "repositoryId"  CHAR(36)        NOT NULL REFERENCES repositories("repositoryId") ON DELETE CASCADE,
+    "topicId"       CHAR(36)        NOT NULL REFERENCES topics("topicId") ON DELETE CASCADE,
+    PRIMARY KEY ("repositoryId", "topicId")
+);
+
+CREATE INDEX repository_topics_topic_id_idx ON repository_topics ("topicId");
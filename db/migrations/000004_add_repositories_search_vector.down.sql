@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS repositories_search_vector_idx;
+ALTER TABLE "repositories" DROP COLUMN IF EXISTS "search_vector";
@@ -0,0 +1,7 @@
+ALTER TABLE "repositories"
+    ADD COLUMN "search_vector" tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', replace(full_name, '/', ' ')), 'A') ||
+        setweight(to_tsvector('english', description), 'B')
+    ) STORED;
+
+CREATE INDEX repositories_search_vector_idx ON repositories USING GIN ("search_vector");
@@ -0,0 +1,2 @@
+ALTER TABLE "repositories"
+    ADD COLUMN "imported_at" timestamptz NOT NULL DEFAULT (NOW());
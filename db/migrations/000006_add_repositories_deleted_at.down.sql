@@ -0,0 +1,2 @@
+DELETE FROM "repositories" WHERE "deleted_at" IS NOT NULL;
+ALTER TABLE "repositories" DROP COLUMN IF EXISTS "deleted_at";
@@ -0,0 +1,2 @@
+ALTER TABLE "repositories"
+    ADD COLUMN "deleted_at" timestamptz NULL;
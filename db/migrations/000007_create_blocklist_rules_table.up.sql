@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS "blocklist_rules"
+(
+    "ruleId"        CHAR(36)        NOT NULL PRIMARY KEY,
+    "kind"          VARCHAR(20)     NOT NULL CHECK ( "kind" IN ('name', 'owner', 'regex', 'topic') ),
+    "pattern"       TEXT            NOT NULL CHECK ( length("pattern") > 0 ),
+    "created_at"    timestamptz     NOT NULL DEFAULT (NOW()),
+    UNIQUE ("kind", "pattern")
+);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS "repositories_archive"
+(
+    "repositoryId"    CHAR(36)        NOT NULL,
+    "language_name"   non_empty,
+    "full_name"       non_empty,
+    "stars"           BIGINT          NOT NULL,
+    "createdAt"       timestamptz     NOT NULL,
+    "owner"           VARCHAR(100)    NOT NULL,
+    "description"     TEXT            NOT NULL,
+    "imported_at"     timestamptz     NOT NULL,
+    "deleted_at"      timestamptz     NULL,
+    "archived_at"     timestamptz     NOT NULL DEFAULT (NOW())
+);
+
+CREATE INDEX repositories_archive_language_name_idx ON repositories_archive (language_name);
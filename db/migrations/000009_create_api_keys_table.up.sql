@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "api_keys"
+(
+    "keyId"         CHAR(36)        NOT NULL PRIMARY KEY,
+    "name"          non_empty,
+    "key_prefix"    VARCHAR(16)     NOT NULL,
+    "key_hash"      CHAR(64)        NOT NULL UNIQUE,
+    "scopes"        TEXT[]          NOT NULL CHECK ( cardinality("scopes") > 0 ),
+    "expires_at"    timestamptz     NULL,
+    "revoked_at"    timestamptz     NULL,
+    "last_used_at"  timestamptz     NULL,
+    "created_at"    timestamptz     NOT NULL DEFAULT (NOW())
+);
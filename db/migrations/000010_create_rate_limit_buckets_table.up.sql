@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS "rate_limit_buckets"
+(
+    "key"           TEXT                NOT NULL PRIMARY KEY,
+    "tokens"        DOUBLE PRECISION    NOT NULL,
+    "allowed"       BOOLEAN             NOT NULL,
+    "updated_at"    timestamptz         NOT NULL DEFAULT (NOW())
+);
+
+CREATE INDEX IF NOT EXISTS rate_limit_buckets_updated_at_idx ON "rate_limit_buckets" ("updated_at");
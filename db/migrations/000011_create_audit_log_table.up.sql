@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS "audit_log"
+(
+    "entryId"       CHAR(36)        NOT NULL PRIMARY KEY,
+    "actor"         non_empty,
+    "action"        VARCHAR(50)     NOT NULL,
+    "targets"       TEXT[]          NOT NULL DEFAULT '{}',
+    "request_id"    TEXT            NULL,
+    "outcome"       VARCHAR(10)     NOT NULL CHECK ( "outcome" IN ('success', 'failure') ),
+    "created_at"    timestamptz     NOT NULL DEFAULT (NOW())
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON "audit_log" ("created_at" DESC, "entryId" DESC);
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON "audit_log" ("actor");
+CREATE INDEX IF NOT EXISTS audit_log_action_idx ON "audit_log" ("action");
@@ -0,0 +1,117 @@
+// Package migrations embeds the sql migrations of this directory and applies them. The applied version is
+// recorded in the schema_migrations table of golang-migrate, so databases migrated by either tool stay compatible.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+var fileName = regexp.MustCompile(`^([0-9]+)_(.+)\.(up|down)\.sql$`)
+
+// Migration represents the up and down statements of one version
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Embedded returns the migrations compiled into the binary sorted by version
+func Embedded() []Migration {
+	migrations, err := Load(files)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return migrations
+}
+
+// LatestVersion returns the version of the latest embedded migration, the schema version the binary expects
+func LatestVersion() uint {
+	migrations := Embedded()
+
+	if len(migrations) == 0 {
+		return 0
+	}
+
+	return migrations[len(migrations)-1].Version
+}
+
+// Load reads the NNNNNN_name.up.sql and NNNNNN_name.down.sql files of the file system sorted by version,
+// every version needs both files
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]*Migration)
+	directions := make(map[uint]map[string]bool)
+
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %s: %w", entry.Name(), err)
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[uint(version)]
+
+		if !ok {
+			m = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = m
+		}
+
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has the names %s and %s", m.Version, m.Name, match[2])
+		}
+
+		if directions[m.Version] == nil {
+			directions[m.Version] = make(map[string]bool)
+		}
+
+		directions[m.Version][match[3]] = true
+
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, m := range byVersion {
+		if !directions[m.Version]["up"] || !directions[m.Version]["down"] {
+			return nil, fmt.Errorf("migration %d_%s needs an up and a down file", m.Version, m.Name)
+		}
+
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
@@ -0,0 +1,103 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000002_add_column.up.sql":    {Data: []byte("ALTER TABLE a ADD COLUMN b INT;")},
+		"000002_add_column.down.sql":  {Data: []byte("ALTER TABLE a DROP COLUMN b;")},
+		"000001_init_schema.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+		"000001_init_schema.down.sql": {Data: []byte("DROP TABLE a;")},
+		"migrations.go":               {Data: []byte("package migrations")},
+	}
+
+	migrations, err := Load(fsys)
+
+	if err != nil {
+		t.Fatalf("Expected no error. Got %v", err)
+	}
+
+	if len(migrations) != 2 || migrations[0].Version != 1 || migrations[1].Version != 2 {
+		t.Fatalf("Expected migrations 1 and 2 sorted by version. Got %+v", migrations)
+	}
+
+	if migrations[1].Name != "add_column" || migrations[1].Down != "ALTER TABLE a DROP COLUMN b;" {
+		t.Errorf("Got unexpected migration %+v", migrations[1])
+	}
+}
+
+func TestLoadWithMissingDownFile(t *testing.T) {
+	fsys := fstest.MapFS{"000001_init_schema.up.sql": {Data: []byte("CREATE TABLE a (id INT);")}}
+
+	if _, err := Load(fsys); err == nil {
+		t.Error("Expected error for migration without down file")
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	migrations := Embedded()
+
+	for i, migration := range migrations {
+		if migration.Version != uint(i+1) {
+			t.Errorf("Expected migration %d at position %d. Got %d", i+1, i, migration.Version)
+		}
+	}
+
+	if LatestVersion() != uint(len(migrations)) {
+		t.Errorf("Expected latest version %d. Got %d", len(migrations), LatestVersion())
+	}
+}
+
+func TestPlan(t *testing.T) {
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 5}}
+
+	tests := []struct {
+		name             string
+		current          uint
+		target           uint
+		expectedVersions []uint
+		expectedUp       bool
+	}{
+		{name: "Test up from empty database", current: 0, target: 5, expectedVersions: []uint{1, 2, 5}, expectedUp: true},
+		{name: "Test up to version", current: 1, target: 2, expectedVersions: []uint{2}, expectedUp: true},
+		{name: "Test up to date", current: 5, target: 5, expectedVersions: nil, expectedUp: true},
+		{name: "Test down to version", current: 5, target: 1, expectedVersions: []uint{2, 1}},
+		{name: "Test down to empty database", current: 2, target: 0, expectedVersions: []uint{1, 0}},
+	}
+
+	for _, test := range tests {
+		steps := plan(migrations, test.current, test.target)
+
+		if len(steps) != len(test.expectedVersions) {
+			t.Errorf("%s: expected %d steps. Got %d", test.name, len(test.expectedVersions), len(steps))
+			continue
+		}
+
+		for i, s := range steps {
+			if s.version != test.expectedVersions[i] || s.up != test.expectedUp {
+				t.Errorf("%s: expected step %d to record version %d. Got %+v", test.name, i, test.expectedVersions[i], s)
+			}
+		}
+	}
+}
+
+func TestAdvisoryLockIDMatchesGolangMigrate(t *testing.T) {
+	tests := []struct {
+		database        string
+		additionalNames []string
+		expectedID      int64
+	}{
+		{database: "database_name", expectedID: 1764327054},
+		{database: "database_name", additionalNames: []string{"schema_name_1"}, expectedID: 2453313553},
+		{database: "database_name", additionalNames: []string{"schema_name_1", "schema_name_2"}, expectedID: 3743845847},
+	}
+
+	for _, test := range tests {
+		if id := advisoryLockID(test.database, test.additionalNames...); id != test.expectedID {
+			t.Errorf("Expected lock id %d for %s. Got %d", test.expectedID, test.database, id)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"log/slog"
+	"strings"
+)
+
+// advisoryLockSalt is the salt of golang-migrate's lock id, using the same id serializes both tools
+const advisoryLockSalt uint32 = 1486364155
+
+// ErrDirty is returned when a previous migration failed halfway, the schema has to be fixed by hand and forced
+var ErrDirty = errors.New("database is dirty")
+
+// Status represents the applied version and the migrations which are not applied yet
+type Status struct {
+	Version uint
+	Dirty   bool
+	Applied []Migration
+	Pending []Migration
+}
+
+// Migrator applies the migrations to the database while holding an advisory lock,
+// so only one of several starting instances migrates
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	logger     *slog.Logger
+}
+
+// NewMigrator creates a migrator of the embedded migrations
+func NewMigrator(db *sql.DB, logger *slog.Logger) *Migrator {
+	return &Migrator{db: db, migrations: Embedded(), logger: logger}
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.latest())
+}
+
+// Down rolls back the latest applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := readVersion(ctx, conn)
+
+		if err != nil {
+			return err
+		}
+
+		if current.Version == 0 {
+			return nil
+		}
+
+		return m.migrate(ctx, conn, current, m.previous(current.Version))
+	})
+}
+
+// To migrates up or down until the version is applied, version 0 rolls back all migrations
+func (m *Migrator) To(ctx context.Context, version uint) error {
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := readVersion(ctx, conn)
+
+		if err != nil {
+			return err
+		}
+
+		return m.migrate(ctx, conn, current, version)
+	})
+}
+
+// Force records the version as cleanly applied without running migrations, it resolves the dirty state
+// after the schema was repaired by hand
+func (m *Migrator) Force(ctx context.Context, version uint) error {
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		return writeVersion(ctx, conn, version, false)
+	})
+}
+
+// Status reads the applied version
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	conn, err := m.db.Conn(ctx)
+
+	if err != nil {
+		return Status{}, err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	if err = ensureVersionTable(ctx, conn); err != nil {
+		return Status{}, err
+	}
+
+	s, err := readVersion(ctx, conn)
+
+	if err != nil {
+		return Status{}, err
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version <= s.Version {
+			s.Applied = append(s.Applied, migration)
+		} else {
+			s.Pending = append(s.Pending, migration)
+		}
+	}
+
+	return s, nil
+}
+
+// migrate runs the steps from the current to the target version, every step is marked dirty before it runs
+// and clean afterwards like golang-migrate does
+func (m *Migrator) migrate(ctx context.Context, conn *sql.Conn, current Status, target uint) error {
+	if current.Dirty {
+		return fmt.Errorf("%w at version %d, repair the schema and force a version", ErrDirty, current.Version)
+	}
+
+	if target < current.Version && m.index(current.Version) < 0 {
+		return fmt.Errorf("database version %d is newer than the migrations of this binary, it can not roll back", current.Version)
+	}
+
+	for _, step := range plan(m.migrations, current.Version, target) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		m.logger.Info("applying migration",
+			slog.Uint64("version", uint64(step.migration.Version)),
+			slog.String("name", step.migration.Name),
+			slog.Bool("up", step.up))
+
+		if err := writeVersion(ctx, conn, step.version, true); err != nil {
+			return err
+		}
+
+		statements := step.migration.Down
+		if step.up {
+			statements = step.migration.Up
+		}
+
+		if _, err := conn.ExecContext(ctx, statements); err != nil {
+			return fmt.Errorf("migration %d_%s failed: %w", step.migration.Version, step.migration.Name, err)
+		}
+
+		if err := writeVersion(ctx, conn, step.version, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// step applies or rolls back one migration, version is recorded afterwards
+type step struct {
+	migration Migration
+	up        bool
+	version   uint
+}
+
+// plan lists the steps from the current to the target version
+func plan(migrations []Migration, current, target uint) []step {
+	var steps []step
+
+	if target >= current {
+		for _, migration := range migrations {
+			if migration.Version > current && migration.Version <= target {
+				steps = append(steps, step{migration: migration, up: true, version: migration.Version})
+			}
+		}
+
+		return steps
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		migration := migrations[i]
+
+		if migration.Version <= current && migration.Version > target {
+			version := uint(0)
+			if i > 0 {
+				version = migrations[i-1].Version
+			}
+
+			steps = append(steps, step{migration: migration, up: false, version: version})
+		}
+	}
+
+	return steps
+}
+
+func (m *Migrator) latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func (m *Migrator) previous(version uint) uint {
+	i := m.index(version)
+
+	if i <= 0 {
+		return 0
+	}
+
+	return m.migrations[i-1].Version
+}
+
+func (m *Migrator) index(version uint) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// withLock runs the function on one connection holding the session advisory lock of the database
+func (m *Migrator) withLock(ctx context.Context, f func(conn *sql.Conn) error) (err error) {
+	conn, err := m.db.Conn(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	var database, schema string
+
+	if err = conn.QueryRowContext(ctx, `SELECT CURRENT_DATABASE(), CURRENT_SCHEMA()`).Scan(&database, &schema); err != nil {
+		return err
+	}
+
+	lockID := advisoryLockID(database, schema, "schema_migrations")
+
+	if _, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+
+	defer func() {
+		_, unlockErr := conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, lockID)
+		err = errors.Join(err, unlockErr)
+	}()
+
+	// migrations may run longer than the statement timeout of the pool, the connection gets it back afterwards
+	if _, err = conn.ExecContext(ctx, `SET statement_timeout = 0`); err != nil {
+		return err
+	}
+
+	defer func() {
+		_, resetErr := conn.ExecContext(context.WithoutCancel(ctx), `RESET statement_timeout`)
+		err = errors.Join(err, resetErr)
+	}()
+
+	if err = ensureVersionTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return f(conn)
+}
+
+// advisoryLockID derives the lock id from the database, schema and table name the way golang-migrate does
+func advisoryLockID(database string, additionalNames ...string) int64 {
+	name := strings.Join(append(additionalNames, database), "\x00")
+	sum := crc32.ChecksumIEEE([]byte(name)) * advisoryLockSalt
+
+	return int64(sum)
+}
+
+func ensureVersionTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`)
+
+	return err
+}
+
+// readVersion reads the single row of the version table, no row is version 0
+func readVersion(ctx context.Context, conn *sql.Conn) (Status, error) {
+	var s Status
+
+	err := conn.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&s.Version, &s.Dirty)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return Status{}, nil
+	}
+
+	return s, err
+}
+
+// writeVersion replaces the recorded version, version 0 without dirty flag removes the row like golang-migrate
+func writeVersion(ctx context.Context, conn *sql.Conn, version uint, dirty bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err = tx.ExecContext(ctx, `TRUNCATE schema_migrations`); err != nil {
+		return err
+	}
+
+	if version != 0 || dirty {
+		if _, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)`, version, dirty); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,56 @@
+// Package pool opens the postgres connection pools configured by config.PoolConfig
+package pool
+
+import (
+	"database/sql"
+	"strconv"
+
+	"github.com/lib/pq"
+
+	"github.com/pavbis/repositories-api/config"
+)
+
+// Open creates the connection pool of the dsn, the statement timeout is sent as runtime parameter
+// when a connection is established and overrides a statement_timeout of the dsn
+func Open(dsn string, cfg config.PoolConfig) (*sql.DB, error) {
+	pqConfig, err := connectorConfig(dsn, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	connector, err := pq.NewConnectorConfig(pqConfig)
+
+	if err != nil {
+		return nil, err
+	}
+
+	db := sql.OpenDB(connector)
+	Configure(db, cfg)
+
+	return db, nil
+}
+
+// Configure applies the pool limits to the pool
+func Configure(db *sql.DB, cfg config.PoolConfig) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+}
+
+func connectorConfig(dsn string, cfg config.PoolConfig) (pq.Config, error) {
+	pqConfig, err := pq.NewConfig(dsn)
+
+	if err != nil || cfg.StatementTimeout <= 0 {
+		return pqConfig, err
+	}
+
+	if pqConfig.Runtime == nil {
+		pqConfig.Runtime = make(map[string]string)
+	}
+
+	pqConfig.Runtime["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+
+	return pqConfig, nil
+}
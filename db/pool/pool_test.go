@@ -0,0 +1,48 @@
+package pool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pavbis/repositories-api/config"
+)
+
+func TestStatementTimeoutOverridesDSN(t *testing.T) {
+	tests := []struct {
+		name     string
+		dsn      string
+		timeout  time.Duration
+		expected string
+	}{
+		{name: "Test timeout of the config", dsn: "host=localhost dbname=testdb", timeout: 2 * time.Second, expected: "2000"},
+		{name: "Test config overrides dsn", dsn: "host=localhost statement_timeout=30", timeout: 15 * time.Second, expected: "15000"},
+		{name: "Test dsn without config timeout", dsn: "postgres://localhost/testdb?statement_timeout=30", expected: "30"},
+		{name: "Test no timeout", dsn: "host=localhost", expected: ""},
+	}
+
+	for _, test := range tests {
+		cfg, err := connectorConfig(test.dsn, config.PoolConfig{StatementTimeout: test.timeout})
+
+		if err != nil {
+			t.Fatalf("%s: expected no error. Got %v", test.name, err)
+		}
+
+		if cfg.Runtime["statement_timeout"] != test.expected {
+			t.Errorf("%s: expected statement timeout %q. Got %q", test.name, test.expected, cfg.Runtime["statement_timeout"])
+		}
+	}
+}
+
+func TestOpenConfiguresPool(t *testing.T) {
+	db, err := Open("host=localhost", config.PoolConfig{MaxOpenConns: 7, MaxIdleConns: 3})
+
+	if err != nil {
+		t.Fatalf("Expected no error. Got %v", err)
+	}
+
+	defer func() { _ = db.Close() }()
+
+	if db.Stats().MaxOpenConnections != 7 {
+		t.Errorf("Expected 7 open connections. Got %d", db.Stats().MaxOpenConnections)
+	}
+}
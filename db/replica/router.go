@@ -0,0 +1,172 @@
+// Package replica routes the read only queries to a postgres read replica with fallback to the primary
+package replica
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// unavailableBackoff is the time the reads stay on the primary after the replica failed
+const unavailableBackoff = 10 * time.Second
+
+// Router is a storage.Executor which sends the queries to the replica. The primary answers them
+// while the replica is unavailable and, with read your writes, until the replica replayed the latest write.
+// Exec always runs on the primary as the replica is read only.
+type Router struct {
+	primary        *sql.DB
+	replica        *sql.DB
+	readYourWrites bool
+	logger         *slog.Logger
+	now            func() time.Time
+
+	mu        sync.Mutex
+	downUntil time.Time
+	// writeLSN is the position of the primary's write ahead log the replica has to replay before it serves reads
+	writeLSN string
+}
+
+// NewRouter creates the router, without replica all queries run on the primary
+func NewRouter(primary, replica *sql.DB, readYourWrites bool, logger *slog.Logger) *Router {
+	return &Router{primary: primary, replica: replica, readYourWrites: readYourWrites, logger: logger, now: time.Now}
+}
+
+// RecordWrite remembers the current write position of the primary so the following reads wait for the replica
+// to catch up, it does nothing unless read your writes is enabled
+func (r *Router) RecordWrite(ctx context.Context) {
+	if r.replica == nil || !r.readYourWrites {
+		return
+	}
+
+	var lsn string
+
+	if err := r.primary.QueryRowContext(ctx, `SELECT pg_current_wal_lsn()::TEXT`).Scan(&lsn); err != nil {
+		r.logger.Warn("reading the write position of the primary failed", slog.String("error", err.Error()))
+		return
+	}
+
+	r.mu.Lock()
+	r.writeLSN = lsn
+	r.mu.Unlock()
+}
+
+func (r *Router) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return r.primary.Exec(query, args...)
+}
+
+func (r *Router) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return r.primary.ExecContext(ctx, query, args...)
+}
+
+func (r *Router) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return r.QueryContext(context.Background(), query, args...)
+}
+
+func (r *Router) QueryRow(query string, args ...interface{}) *sql.Row {
+	return r.QueryRowContext(context.Background(), query, args...)
+}
+
+func (r *Router) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if r.useReplica(ctx) {
+		rows, err := r.replica.QueryContext(ctx, query, args...)
+
+		if !r.failed(ctx, err) {
+			return rows, err
+		}
+	}
+
+	return r.primary.QueryContext(ctx, query, args...)
+}
+
+func (r *Router) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if r.useReplica(ctx) {
+		row := r.replica.QueryRowContext(ctx, query, args...)
+
+		if !r.failed(ctx, row.Err()) {
+			return row
+		}
+	}
+
+	return r.primary.QueryRowContext(ctx, query, args...)
+}
+
+// useReplica reports whether the replica is available and replayed the latest recorded write
+func (r *Router) useReplica(ctx context.Context) bool {
+	if r.replica == nil {
+		return false
+	}
+
+	r.mu.Lock()
+	down := r.now().Before(r.downUntil)
+	lsn := r.writeLSN
+	r.mu.Unlock()
+
+	if down {
+		return false
+	}
+
+	if lsn == "" {
+		return true
+	}
+
+	// the replay position is null when the server is not in recovery, then it has all writes
+	var replayed bool
+	err := r.replica.QueryRowContext(ctx, `SELECT COALESCE(pg_last_wal_replay_lsn() >= $1::pg_lsn, TRUE)`, lsn).Scan(&replayed)
+
+	if r.failed(ctx, err) || err != nil || !replayed {
+		return false
+	}
+
+	r.mu.Lock()
+	if r.writeLSN == lsn {
+		r.writeLSN = ""
+	}
+	r.mu.Unlock()
+
+	return true
+}
+
+// failed reports whether the replica is unavailable and marks it down for the backoff,
+// other errors like timeouts or invalid queries would fail on the primary as well
+func (r *Router) failed(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || !unavailable(err) {
+		return false
+	}
+
+	r.mu.Lock()
+	wasUp := !r.now().Before(r.downUntil)
+	r.downUntil = r.now().Add(unavailableBackoff)
+	r.mu.Unlock()
+
+	if wasUp {
+		r.logger.Warn("replica unavailable, reading from the primary",
+			slog.String("error", err.Error()), slog.Duration("backoff", unavailableBackoff))
+	}
+
+	return true
+}
+
+// unavailable reports connection failures, shutdowns and recovery conflicts of the replica
+func unavailable(err error) bool {
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &netErr) {
+		return true
+	}
+
+	var sqlErr interface{ SQLState() string }
+	if !errors.As(err, &sqlErr) {
+		return false
+	}
+
+	state := sqlErr.SQLState()
+
+	// 08 connection exception, 57P0x shutdown or not accepting connections yet, 40001 canceled by recovery conflict
+	return strings.HasPrefix(state, "08") || strings.HasPrefix(state, "57P0") || state == "40001"
+}
@@ -0,0 +1,260 @@
+package replica
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer answers the queries of a fake connection with the configured values or error
+type fakeServer struct {
+	mu      sync.Mutex
+	name    string
+	err     error
+	replay  bool
+	queries []string
+}
+
+func (f *fakeServer) Connect(context.Context) (driver.Conn, error) { return &fakeConn{server: f}, nil }
+func (f *fakeServer) Driver() driver.Driver                        { return nil }
+
+func (f *fakeServer) answer(query string) (driver.Rows, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.queries = append(f.queries, query)
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	switch {
+	case strings.Contains(query, "pg_current_wal_lsn"):
+		return &fakeRows{value: "0/3000060"}, nil
+	case strings.Contains(query, "pg_last_wal_replay_lsn"):
+		return &fakeRows{value: f.replay}, nil
+	}
+
+	return &fakeRows{value: f.name}, nil
+}
+
+func (f *fakeServer) fail(err error) {
+	f.mu.Lock()
+	f.err = err
+	f.mu.Unlock()
+}
+
+func (f *fakeServer) count(part string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := 0
+	for _, query := range f.queries {
+		if strings.Contains(query, part) {
+			n++
+		}
+	}
+
+	return n
+}
+
+type fakeConn struct{ server *fakeServer }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if _, err := c.server.answer(query); err != nil {
+		return nil, err
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	return c.server.answer(query)
+}
+
+type fakeRows struct {
+	value driver.Value
+	done  bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"value"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+
+	r.done = true
+	dest[0] = r.value
+
+	return nil
+}
+
+type stateError string
+
+func (e stateError) Error() string    { return "pq: " + string(e) }
+func (e stateError) SQLState() string { return string(e) }
+
+func newTestRouter(t *testing.T, readYourWrites bool) (*Router, *fakeServer, *fakeServer, *time.Time) {
+	primary := &fakeServer{name: "primary"}
+	replica := &fakeServer{name: "replica", replay: true}
+	primaryDB, replicaDB := sql.OpenDB(primary), sql.OpenDB(replica)
+
+	t.Cleanup(func() {
+		_ = primaryDB.Close()
+		_ = replicaDB.Close()
+	})
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	router := NewRouter(primaryDB, replicaDB, readYourWrites, slog.New(slog.DiscardHandler))
+	router.now = func() time.Time { return now }
+
+	return router, primary, replica, &now
+}
+
+func readFrom(t *testing.T, router *Router) string {
+	var name string
+
+	if err := router.QueryRowContext(context.Background(), "SELECT name").Scan(&name); err != nil {
+		t.Fatalf("Expected no error. Got %v", err)
+	}
+
+	return name
+}
+
+func TestRouterReadsFromReplica(t *testing.T) {
+	router, _, _, _ := newTestRouter(t, false)
+
+	if got := readFrom(t, router); got != "replica" {
+		t.Errorf("Expected the read from the replica. Got %s", got)
+	}
+
+	rows, err := router.QueryContext(context.Background(), "SELECT name")
+	if err != nil {
+		t.Fatalf("Expected no error. Got %v", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var name string
+	if !rows.Next() || rows.Scan(&name) != nil || name != "replica" {
+		t.Errorf("Expected the rows from the replica. Got %q", name)
+	}
+}
+
+func TestRouterWithoutReplicaReadsFromPrimary(t *testing.T) {
+	primary := &fakeServer{name: "primary"}
+	primaryDB := sql.OpenDB(primary)
+	defer func() { _ = primaryDB.Close() }()
+
+	router := NewRouter(primaryDB, nil, true, slog.New(slog.DiscardHandler))
+	router.RecordWrite(context.Background())
+
+	if got := readFrom(t, router); got != "primary" {
+		t.Errorf("Expected the read from the primary. Got %s", got)
+	}
+
+	if primary.count("pg_current_wal_lsn") != 0 {
+		t.Error("Expected no write position to be recorded without replica")
+	}
+}
+
+func TestRouterFallsBackToPrimary(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "connection exception", err: stateError("08006")},
+		{name: "admin shutdown", err: stateError("57P01")},
+		{name: "recovery conflict", err: stateError("40001")},
+		{name: "bad connection", err: driver.ErrBadConn},
+		{name: "closed connection", err: io.ErrUnexpectedEOF},
+	}
+
+	for _, test := range tests {
+		router, _, replica, now := newTestRouter(t, false)
+		replica.fail(test.err)
+
+		if got := readFrom(t, router); got != "primary" {
+			t.Errorf("for fallback test '%s', got the read from %s but expected the primary", test.name, got)
+		}
+
+		replica.fail(nil)
+		*now = now.Add(time.Second)
+
+		if got := readFrom(t, router); got != "primary" {
+			t.Errorf("for fallback test '%s', got the read from %s during the backoff but expected the primary", test.name, got)
+		}
+
+		*now = now.Add(unavailableBackoff)
+
+		if got := readFrom(t, router); got != "replica" {
+			t.Errorf("for fallback test '%s', got the read from %s after the backoff but expected the replica", test.name, got)
+		}
+	}
+}
+
+func TestRouterDoesNotFallBackOnQueryErrors(t *testing.T) {
+	router, primary, replica, _ := newTestRouter(t, false)
+	replica.fail(stateError("57014"))
+
+	if _, err := router.QueryContext(context.Background(), "SELECT name"); err == nil {
+		t.Fatal("Expected the statement timeout of the replica")
+	}
+
+	if primary.count("SELECT name") != 0 {
+		t.Error("Expected the query not to be repeated on the primary")
+	}
+}
+
+func TestRouterExecutesOnPrimary(t *testing.T) {
+	router, primary, replica, _ := newTestRouter(t, false)
+
+	if _, err := router.ExecContext(context.Background(), "DELETE FROM repositories"); err != nil {
+		t.Fatalf("Expected no error. Got %v", err)
+	}
+
+	if primary.count("DELETE") != 1 || replica.count("DELETE") != 0 {
+		t.Error("Expected the statement to run on the primary")
+	}
+}
+
+func TestRouterReadsYourWrites(t *testing.T) {
+	router, primary, replica, _ := newTestRouter(t, true)
+	replica.replay = false
+
+	router.RecordWrite(context.Background())
+
+	if got := readFrom(t, router); got != "primary" {
+		t.Errorf("Expected the read from the primary while the replica lags. Got %s", got)
+	}
+
+	replica.replay = true
+
+	if got := readFrom(t, router); got != "replica" {
+		t.Errorf("Expected the read from the replica once it replayed the write. Got %s", got)
+	}
+
+	if got := readFrom(t, router); got != "replica" {
+		t.Errorf("Expected the read from the replica. Got %s", got)
+	}
+
+	if replica.count("pg_last_wal_replay_lsn") != 2 {
+		t.Errorf("Expected the replication lag to be checked until the replica caught up. Got %d checks", replica.count("pg_last_wal_replay_lsn"))
+	}
+
+	if primary.count("pg_current_wal_lsn") != 1 {
+		t.Error("Expected the write position to be read from the primary")
+	}
+}
@@ -1,9 +1,11 @@
 package main
 
-import "github.com/pavbis/repositories-api/api"
+import (
+	"os"
+
+	"github.com/pavbis/repositories-api/cli"
+)
 
 func main() {
-	s := api.Server{}
-	s.Initialize()
-	s.Run(":7000")
+	os.Exit(cli.Run(os.Args[1:], cli.Env{LookupEnv: os.LookupEnv, Stdout: os.Stdout, Stderr: os.Stderr}))
 }
@@ -0,0 +1,209 @@
+// Package metrics collects the prometheus metrics of the api, the GitHub client, the imports and the database
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/pavbis/repositories-api/application/storage"
+	"github.com/pavbis/repositories-api/application/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "repositories_api"
+
+// Metrics holds the collectors of the service in its own registry
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	gitHubRequests           *prometheus.CounterVec
+	gitHubDuration           prometheus.Histogram
+	gitHubRateLimitRemaining prometheus.Gauge
+
+	importDuration     *prometheus.HistogramVec
+	importRepositories *prometheus.CounterVec
+}
+
+// New creates the metrics and registers the runtime, process and collectors of the named db pools
+func New(counter storage.CountsRepositoriesPerLanguage, pools map[string]*sql.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of answered http requests by route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of the http requests by route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		gitHubRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "github_requests_total",
+			Help:      "Number of GitHub api requests by status code, error if no response was received.",
+		}, []string{"status"}),
+		gitHubDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "github_request_duration_seconds",
+			Help:      "Latency of the GitHub api requests.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		gitHubRateLimitRemaining: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "github_rate_limit_remaining",
+			Help:      "Remaining GitHub api requests of the current rate limit window as reported by the last response.",
+		}),
+		importDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "import_duration_seconds",
+			Help:      "Duration of the language imports by outcome.",
+			Buckets:   []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120},
+		}, []string{"language", "outcome"}),
+		importRepositories: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "import_repositories_total",
+			Help:      "Number of imported repositories by language and kind, kind is fetched, persisted or filtered.",
+		}, []string{"language", "kind"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		newRepositoriesCollector(counter),
+		m.httpRequests,
+		m.httpDuration,
+		m.gitHubRequests,
+		m.gitHubDuration,
+		m.gitHubRateLimitRemaining,
+		m.importDuration,
+		m.importRepositories,
+	)
+
+	for name, db := range pools {
+		m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+	}
+
+	return m
+}
+
+// Handler exposes the metrics in the prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// HTTPMiddleware counts the requests and observes their latency by chi route pattern,
+// requests without matching route share one label so unknown paths can not create new series
+func (m *Metrics) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		m.httpRequests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		m.httpDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// InstrumentGitHubTransport observes the requests sent to the GitHub api through the transport
+func (m *Metrics) InstrumentGitHubTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next.RoundTrip(r)
+		m.gitHubDuration.Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			m.gitHubRequests.WithLabelValues("error").Inc()
+			return nil, err
+		}
+
+		m.gitHubRequests.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
+
+		if remaining, err := strconv.ParseFloat(resp.Header.Get("X-RateLimit-Remaining"), 64); err == nil {
+			m.gitHubRateLimitRemaining.Set(remaining)
+		}
+
+		return resp, nil
+	})
+}
+
+// ObserveImport records the duration and the repository counts of a finished import
+func (m *Metrics) ObserveImport(language string, result types.ImportResult, duration time.Duration, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+
+	m.importDuration.WithLabelValues(language, outcome).Observe(duration.Seconds())
+	m.importRepositories.WithLabelValues(language, "fetched").Add(float64(result.FetchedRepositories))
+	m.importRepositories.WithLabelValues(language, "persisted").Add(float64(result.PersistedRepositories))
+	m.importRepositories.WithLabelValues(language, "filtered").Add(float64(result.FilteredRepositories))
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// repositoriesCollector counts the repositories per language on every scrape
+type repositoriesCollector struct {
+	counter storage.CountsRepositoriesPerLanguage
+	desc    *prometheus.Desc
+}
+
+func newRepositoriesCollector(counter storage.CountsRepositoriesPerLanguage) *repositoriesCollector {
+	return &repositoriesCollector{
+		counter: counter,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "repositories"),
+			"Number of stored repositories per language.",
+			[]string{"language"}, nil),
+	}
+}
+
+func (c *repositoriesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *repositoriesCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	counts, err := c.counter.CountRepositoriesPerLanguage(ctx)
+
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+
+	for language, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), language)
+	}
+}
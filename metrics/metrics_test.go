@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	// nolint: goimports
+	_ "github.com/lib/pq"
+
+	"github.com/pavbis/repositories-api/application/types"
+)
+
+type fakeRepositoriesCounter struct {
+	counts map[string]int64
+	err    error
+}
+
+func (f fakeRepositoriesCounter) CountRepositoriesPerLanguage(_ context.Context) (map[string]int64, error) {
+	return f.counts, f.err
+}
+
+func newTestMetrics(t *testing.T, counter fakeRepositoriesCounter) *Metrics {
+	db, err := sql.Open("postgres", "postgres://localhost/metrics?sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	return New(counter, map[string]*sql.DB{"write": db})
+}
+
+func scrape(t *testing.T, m *Metrics) string {
+	res := httptest.NewRecorder()
+	m.Handler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	return res.Body.String()
+}
+
+func assertContains(t *testing.T, body string, lines ...string) {
+	for _, line := range lines {
+		if !strings.Contains(body, line) {
+			t.Errorf("Expected metrics to contain %q", line)
+		}
+	}
+}
+
+func TestHTTPMiddlewareUsesRoutePattern(t *testing.T) {
+	m := newTestMetrics(t, fakeRepositoriesCounter{})
+	router := chi.NewRouter()
+	router.Use(m.HTTPMiddleware)
+	router.Get("/api/languages/{languageName}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	for _, path := range []string{"/api/languages/go", "/api/languages/php", "/unknown"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assertContains(t, scrape(t, m),
+		`repositories_api_http_requests_total{method="GET",route="/api/languages/{languageName}",status="204"} 2`,
+		`repositories_api_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`repositories_api_http_request_duration_seconds_count{method="GET",route="/api/languages/{languageName}"} 2`,
+	)
+}
+
+func TestInstrumentGitHubTransport(t *testing.T) {
+	m := newTestMetrics(t, fakeRepositoriesCounter{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := http.Client{Transport: m.InstrumentGitHubTransport(nil)}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if _, err = client.Get("http://127.0.0.1:0"); err == nil {
+		t.Fatal("Expected request to an invalid address to fail")
+	}
+
+	assertContains(t, scrape(t, m),
+		`repositories_api_github_requests_total{status="403"} 1`,
+		`repositories_api_github_requests_total{status="error"} 1`,
+		`repositories_api_github_rate_limit_remaining 42`,
+		`repositories_api_github_request_duration_seconds_count 2`,
+	)
+}
+
+func TestObserveImport(t *testing.T) {
+	m := newTestMetrics(t, fakeRepositoriesCounter{})
+	m.ObserveImport("go", types.ImportResult{FetchedRepositories: 5, PersistedRepositories: 4, FilteredRepositories: 1}, time.Second, nil)
+	m.ObserveImport("go", types.ImportResult{}, time.Second, errors.New("failed"))
+
+	assertContains(t, scrape(t, m),
+		`repositories_api_import_duration_seconds_count{language="go",outcome="success"} 1`,
+		`repositories_api_import_duration_seconds_count{language="go",outcome="failure"} 1`,
+		`repositories_api_import_repositories_total{kind="fetched",language="go"} 5`,
+		`repositories_api_import_repositories_total{kind="persisted",language="go"} 4`,
+		`repositories_api_import_repositories_total{kind="filtered",language="go"} 1`,
+	)
+}
+
+func TestRepositoriesAndDBStatsCollectors(t *testing.T) {
+	m := newTestMetrics(t, fakeRepositoriesCounter{counts: map[string]int64{"go": 3, "php": 0}})
+
+	assertContains(t, scrape(t, m),
+		`repositories_api_repositories{language="go"} 3`,
+		`repositories_api_repositories{language="php"} 0`,
+		`go_sql_max_open_connections{db_name="write"} 0`,
+	)
+}
+
+func TestRepositoriesCollectorError(t *testing.T) {
+	m := newTestMetrics(t, fakeRepositoriesCounter{err: errors.New("database is down")})
+
+	res := httptest.NewRecorder()
+	m.Handler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if res.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, res.Code)
+	}
+}
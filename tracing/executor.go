@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/pavbis/repositories-api/application/storage"
+)
+
+// tracingExecutor starts a span for every statement, statements without context become children of the bound context
+type tracingExecutor struct {
+	ctx      context.Context
+	executor storage.Executor
+}
+
+// NewExecutor wraps the executor so its statements are traced within the provided context,
+// usually the context of the request the executor is passed to
+func NewExecutor(ctx context.Context, executor storage.Executor) storage.Executor {
+	return &tracingExecutor{ctx: ctx, executor: executor}
+}
+
+func (e *tracingExecutor) Exec(query string, args ...interface{}) (sql.Result, error) {
+	span := startSpan(e.ctx, query)
+	result, err := e.executor.Exec(query, args...)
+	endSpan(span, err)
+
+	return result, err
+}
+
+func (e *tracingExecutor) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	span := startSpan(e.ctx, query)
+	rows, err := e.executor.Query(query, args...)
+	endSpan(span, err)
+
+	return rows, err
+}
+
+func (e *tracingExecutor) QueryRow(query string, args ...interface{}) *sql.Row {
+	span := startSpan(e.ctx, query)
+	row := e.executor.QueryRow(query, args...)
+	endSpan(span, row.Err())
+
+	return row
+}
+
+func (e *tracingExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	span := startSpan(ctx, query)
+	result, err := e.executor.ExecContext(ctx, query, args...)
+	endSpan(span, err)
+
+	return result, err
+}
+
+func (e *tracingExecutor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	span := startSpan(ctx, query)
+	rows, err := e.executor.QueryContext(ctx, query, args...)
+	endSpan(span, err)
+
+	return rows, err
+}
+
+func (e *tracingExecutor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	span := startSpan(ctx, query)
+	row := e.executor.QueryRowContext(ctx, query, args...)
+	endSpan(span, row.Err())
+
+	return row
+}
+
+// startSpan names the span by the sql operation, the statements are built with placeholders
+// so the query text holds no values
+func startSpan(ctx context.Context, query string) trace.Span {
+	operation := "QUERY"
+	if fields := strings.Fields(query); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+
+	_, span := tracer().Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(strings.Join(strings.Fields(query), " ")),
+		))
+
+	return span
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,168 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/pavbis/repositories-api/config"
+)
+
+// recordSpans installs a tracer provider which records the ended spans of the test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+
+	return recorder
+}
+
+type fakeExecutor struct {
+	err error
+}
+
+func (f fakeExecutor) Exec(_ string, _ ...interface{}) (sql.Result, error) {
+	return nil, f.err
+}
+
+func (f fakeExecutor) Query(_ string, _ ...interface{}) (*sql.Rows, error) {
+	return nil, f.err
+}
+
+func (f fakeExecutor) QueryRow(_ string, _ ...interface{}) *sql.Row {
+	return nil
+}
+
+func (f fakeExecutor) ExecContext(_ context.Context, _ string, _ ...interface{}) (sql.Result, error) {
+	return nil, f.err
+}
+
+func (f fakeExecutor) QueryContext(_ context.Context, _ string, _ ...interface{}) (*sql.Rows, error) {
+	return nil, f.err
+}
+
+func (f fakeExecutor) QueryRowContext(_ context.Context, _ string, _ ...interface{}) *sql.Row {
+	return nil
+}
+
+func TestMiddlewareNamesSpanByRoutePattern(t *testing.T) {
+	recorder := recordSpans(t)
+	router := chi.NewRouter()
+	router.Use(Middleware)
+	router.Get("/api/languages/{languageName}", func(w http.ResponseWriter, r *http.Request) {})
+
+	parent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := httptest.NewRequest(http.MethodGet, "/api/languages/go", nil)
+	req.Header.Set("traceparent", parent)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span. Got %d", len(spans))
+	}
+
+	if spans[0].Name() != "GET /api/languages/{languageName}" {
+		t.Errorf("Expected span named by route pattern. Got %s", spans[0].Name())
+	}
+
+	if spans[0].SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected the trace of the caller to be continued. Got %s", spans[0].SpanContext().TraceID())
+	}
+}
+
+func TestTransportPropagatesTraceContext(t *testing.T) {
+	recorder := recordSpans(t)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "import")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	client := http.Client{Transport: Transport(nil)}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	parent.End()
+
+	if traceparent == "" {
+		t.Fatal("Expected traceparent header to be sent")
+	}
+
+	spans := recorder.Ended()
+
+	if len(spans) != 2 || spans[0].Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("Expected client span as child of the import span. Got %d spans", len(spans))
+	}
+}
+
+func TestExecutorTracesStatements(t *testing.T) {
+	recorder := recordSpans(t)
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+
+	_, _ = NewExecutor(ctx, fakeExecutor{}).Query("SELECT 1 FROM programming_languages WHERE language_name = $1", "go")
+	_, _ = NewExecutor(ctx, fakeExecutor{err: errors.New("connection refused")}).ExecContext(ctx, "\n  INSERT INTO audit_log VALUES ($1)", "x")
+	_, _ = NewExecutor(ctx, fakeExecutor{err: sql.ErrNoRows}).QueryContext(ctx, "select 1")
+	parent.End()
+
+	tests := []struct {
+		name           string
+		expectedStatus codes.Code
+	}{
+		{name: "SELECT", expectedStatus: codes.Unset},
+		{name: "INSERT", expectedStatus: codes.Error},
+		{name: "SELECT", expectedStatus: codes.Unset},
+	}
+
+	spans := recorder.Ended()
+
+	for i, test := range tests {
+		span := spans[i]
+
+		if span.Name() != test.name || span.Status().Code != test.expectedStatus {
+			t.Errorf("Expected span %s with status %v. Got %s with %v", test.name, test.expectedStatus, span.Name(), span.Status().Code)
+		}
+
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() || span.SpanKind() != trace.SpanKindClient {
+			t.Errorf("Expected client span %s as child of the request span", span.Name())
+		}
+	}
+}
+
+func TestSetupWithoutExporter(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Exporter: "none"})
+
+	if err != nil {
+		t.Fatalf("Expected no error. Got %v", err)
+	}
+
+	if err = shutdown(context.Background()); err != nil {
+		t.Errorf("Expected no error on shutdown. Got %v", err)
+	}
+}